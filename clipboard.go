@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// maxOSC52Length is the length of the longest OSC 52 escape sequence which is
+// written to the terminal. Many terminals ignore longer sequences.
+const maxOSC52Length = 100000
+
+// clipboardText is text waiting to be copied to the clipboard and a
+// description of it for the help line.
+type clipboardText struct {
+	what string
+	text string
+}
+
+// writeOSC52 writes the escape sequence which copies text to the clipboard to
+// tty. It fails if there is no terminal or if the sequence is too long.
+func writeOSC52(tty io.Writer, text string) error {
+	if tty == nil {
+		return errors.New("no terminal")
+	}
+
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if len(seq) > maxOSC52Length {
+		return errors.New("text is too long to copy through the terminal")
+	}
+
+	_, err := io.WriteString(tty, seq)
+	return err
+}
+
+// runClipboardCommand copies text to the clipboard by writing it to the
+// standard input of command. The output of the command is discarded, since
+// commands such as xclip keep running in the background to serve the
+// clipboard.
+func runClipboardCommand(command []string, text string) error {
+	if len(command) == 0 {
+		return errors.New("no clipboard-command is set")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", command[0], err)
+	}
+
+	return nil
+}
+
+// formatValue returns v as jq would write it with the given options:
+// compact or indented, and strings without quotes for raw output.
+func formatValue(v any, opts options.Options) string {
+	if s, ok := v.(string); ok && bool(opts.RawOutput) {
+		return s
+	}
+
+	indent := strings.Repeat(" ", options.EffectiveInt(&opts.Indent))
+	if bool(opts.CompactOutput) || (indent == "" && !bool(opts.Tab)) {
+		return compactJSON(v)
+	}
+
+	if opts.Tab {
+		indent = "\t"
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func TestWriteOSC52(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, writeOSC52(&b, ".items[0]"))
+	assert.Equal(t, "\x1b]52;c;Lml0ZW1zWzBd\a", b.String())
+
+	assert.Error(t, writeOSC52(nil, "x"))
+
+	b.Reset()
+	assert.Error(t, writeOSC52(&b, strings.Repeat("x", maxOSC52Length)))
+	assert.Zero(t, b.Len())
+}
+
+func TestRunClipboardCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on sh")
+	}
+
+	path := filepath.Join(t.TempDir(), "clipboard")
+	require.NoError(t, runClipboardCommand([]string{"sh", "-c", `cat > "$0"`, path}, "copied\n"))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "copied\n", string(contents))
+
+	assert.Error(t, runClipboardCommand([]string{"false"}, "x"))
+	assert.Error(t, runClipboardCommand(nil, "x"))
+}
+
+func TestFormatValue(t *testing.T) {
+	v := orderedObject{{key: "b", value: []any{json.Number("1")}}, {key: "a", value: "<x>"}}
+
+	assert.Equal(t, "{\n  \"b\": [\n    1\n  ],\n  \"a\": \"<x>\"\n}", formatValue(v, options.Options{}))
+	assert.Equal(t, `{"b":[1],"a":"<x>"}`, formatValue(v, options.Options{CompactOutput: true}))
+	assert.Equal(t, "{\n\t\"b\": [\n\t\t1\n\t],\n\t\"a\": \"<x>\"\n}", formatValue(v, options.Options{Tab: true}))
+	assert.Equal(t, "[\n    1\n]", formatValue([]any{json.Number("1")}, options.Options{Indent: options.Indent{Value: 4, IsSet: true}}))
+
+	opts := options.Options{}
+	require.NoError(t, opts.Indent.Set("0"))
+	assert.Equal(t, `{"b":[1],"a":"<x>"}`, formatValue(v, opts))
+
+	assert.Equal(t, `"a\nb"`, formatValue("a\nb", options.Options{}))
+	assert.Equal(t, "a\nb", formatValue("a\nb", options.Options{RawOutput: true}))
+	assert.Equal(t, "null", formatValue(nil, options.Options{RawOutput: true}))
+}
@@ -0,0 +1,866 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"container/list"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rivo/uniseg"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// builtinSummary holds the signatures and summaries of the builtins from the
+// jq manual, one per line, separated by a tab.
+//
+//go:embed builtins.txt
+var builtinSummary string
+
+// builtin is a builtin function of jq and the number of arguments it takes.
+type builtin struct {
+	name  string
+	arity int
+
+	// signature is the function as written in the jq manual, e.g.
+	// select(f), and description is its summary. Both are empty for
+	// builtins missing from the manual.
+	signature   string
+	description string
+}
+
+// documentedBuiltins are the builtins in the summary of the jq manual, keyed
+// by name/arity.
+var documentedBuiltins = parseBuiltinSummary(builtinSummary)
+
+// parseBuiltinSummary parses the summary of the jq manual. Empty lines and
+// lines starting with # are skipped.
+func parseBuiltinSummary(summary string) map[string]builtin {
+	builtins := make(map[string]builtin)
+	for line := range strings.Lines(summary) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		signature, description, _ := strings.Cut(line, "\t")
+		name, params, ok := strings.Cut(signature, "(")
+		arity := 0
+		if ok {
+			arity = strings.Count(params, ";") + 1
+		}
+
+		b := builtin{name: name, arity: arity, signature: signature, description: strings.TrimSpace(description)}
+		builtins[b.key()] = b
+	}
+
+	return builtins
+}
+
+// key returns the name and arity of b as jq writes them, e.g. select/1.
+func (b builtin) key() string {
+	return fmt.Sprintf("%s/%d", b.name, b.arity)
+}
+
+// Signature returns the signature of b from the jq manual, or its name and
+// arity if it is not documented.
+func (b builtin) Signature() string {
+	return cmp.Or(b.signature, b.key())
+}
+
+// parseBuiltins returns the builtins named by the output of jq's builtins
+// function, sorted by name and arity. Internal functions, whose names start
+// with an underscore, are left out.
+func parseBuiltins(keys []string) []builtin {
+	var builtins []builtin
+	for _, key := range keys {
+		i := strings.LastIndexByte(key, '/')
+		if i <= 0 || strings.HasPrefix(key, "_") {
+			continue
+		}
+
+		arity, err := strconv.Atoi(key[i+1:])
+		if err != nil {
+			continue
+		}
+
+		b, ok := documentedBuiltins[key]
+		if !ok {
+			b = builtin{name: key[:i], arity: arity}
+		}
+
+		builtins = append(builtins, b)
+	}
+
+	slices.SortFunc(builtins, func(a, b builtin) int {
+		return cmp.Or(strings.Compare(a.name, b.name), cmp.Compare(a.arity, b.arity))
+	})
+
+	return slices.CompactFunc(builtins, func(a, b builtin) bool {
+		return a.name == b.name && a.arity == b.arity
+	})
+}
+
+// loadBuiltins returns the builtins of the engine and jq binary set in opts,
+// so that completions match the version of jq which runs the filter.
+func loadBuiltins(ctx context.Context, opts options.Options) ([]builtin, error) {
+	opts = options.Options{
+		Engine:        opts.Engine,
+		JQCommand:     opts.JQCommand,
+		NullInput:     true,
+		CompactOutput: true,
+		Monochrome:    true,
+	}
+
+	var buf bytes.Buffer
+	if err := newEvaluator(opts).Evaluate(ctx, "", "builtins", opts, &buf); err != nil {
+		if stderr, ok := errorOutput(err); ok && stderr != "" {
+			return nil, fmt.Errorf("%s", strings.TrimSpace(stderr))
+		}
+
+		return nil, err
+	}
+
+	var keys []string
+	if err := json.Unmarshal(buf.Bytes(), &keys); err != nil {
+		return nil, err
+	}
+
+	return parseBuiltins(keys), nil
+}
+
+// summaryBuiltins returns the builtins from the summary of the jq manual, for
+// when the engine cannot list its own.
+func summaryBuiltins() []builtin {
+	keys := make([]string, 0, len(documentedBuiltins))
+	for key := range documentedBuiltins {
+		keys = append(keys, key)
+	}
+
+	return parseBuiltins(keys)
+}
+
+// builtinSet holds the builtins filters can be completed with. The builtins
+// are replaced when the engine changes.
+type builtinSet struct {
+	mu       sync.Mutex
+	builtins []builtin
+
+	// generation is increased for each load so that a slow load does not
+	// replace the builtins of a later one
+	generation int
+}
+
+// Load replaces the builtins with those of the engine set in opts, or with
+// those from the jq manual if the engine fails to list them.
+func (s *builtinSet) Load(ctx context.Context, opts options.Options) {
+	s.mu.Lock()
+	s.generation++
+	generation := s.generation
+	s.mu.Unlock()
+
+	builtins, err := loadBuiltins(ctx, opts)
+	if err != nil {
+		builtins = summaryBuiltins()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation == s.generation {
+		s.builtins = builtins
+	}
+}
+
+// Complete returns the builtins whose names start with prefix. Builtins with
+// the same name are returned one after the other.
+func (s *builtinSet) Complete(prefix string) []builtin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matches []builtin
+	for _, b := range s.builtins {
+		if strings.HasPrefix(b.name, prefix) {
+			matches = append(matches, b)
+		}
+	}
+
+	return matches
+}
+
+// keysFilter returns a filter which counts the objects produced by input
+// which have each key. At most 1000 objects are looked at. directives are
+// the import and include directives input depends on.
+func keysFilter(directives, input string) string {
+	if directives != "" {
+		directives += " "
+	}
+
+	return directives + "reduce (limit(1000; try (" + input + ") | objects) | keys[]) as $k ({}; .[$k] += 1)"
+}
+
+// decodeKeys decodes the output of the filter returned by keysFilter, which
+// holds the counts of the keys for each input value, into the counts of the
+// keys of all of them.
+func decodeKeys(output []byte) (map[string]int, error) {
+	keys := make(map[string]int)
+	dec := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var more map[string]int
+		if err := dec.Decode(&more); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for key, n := range more {
+			keys[key] += n
+		}
+	}
+
+	return keys, nil
+}
+
+// keyCacheSize is the number of filters whose keys are kept for completion.
+const keyCacheSize = 128
+
+// keyCacheKey identifies the keys of the values produced by a filter.
+type keyCacheKey struct {
+	// input is the filter which looks up the keys, and options the
+	// options which change its output, as returned by keyOptions
+	input   string
+	options string
+
+	// generation is increased each time the input of the document
+	// changes, e.g. when it is reloaded or another tab is selected
+	generation int
+}
+
+// keyOptions returns the options of opts which change the values a filter
+// produces from the same input, in the form of a string.
+func keyOptions(opts options.Options) string {
+	relevant := options.Options{
+		NullInput:          opts.NullInput,
+		Slurp:              opts.Slurp,
+		RawInput:           opts.RawInput,
+		Stream:             opts.Stream,
+		StreamErrors:       opts.StreamErrors,
+		Seq:                opts.Seq,
+		LibraryPaths:       opts.LibraryPaths,
+		StringArgs:         opts.StringArgs,
+		JSONArgs:           opts.JSONArgs,
+		SlurpFiles:         opts.SlurpFiles,
+		RawFiles:           opts.RawFiles,
+		PositionalArgs:     opts.PositionalArgs,
+		PositionalJSONArgs: opts.PositionalJSONArgs,
+		Positional:         opts.Positional,
+	}
+
+	flags := append(relevant.ToSlice(), string(opts.Engine), string(opts.JQCommand), string(opts.InputFormat))
+	return strings.Join(flags, "\x00")
+}
+
+// keyCache holds the keys of the values produced by recently completed
+// filters. The least recently used keys are evicted once it is full.
+type keyCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *keyCacheEntry, most recently used first
+	entries map[keyCacheKey]*list.Element
+
+	// pending holds the filters whose keys are being looked up
+	pending map[keyCacheKey]bool
+}
+
+type keyCacheEntry struct {
+	key  keyCacheKey
+	keys map[string]int
+}
+
+func newKeyCache(size int) *keyCache {
+	return &keyCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[keyCacheKey]*list.Element),
+		pending: make(map[keyCacheKey]bool),
+	}
+}
+
+// Get returns the keys stored for key, with the number of objects which have
+// each of them, and whether there are any.
+func (c *keyCache) Get(key keyCacheKey) (map[string]int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*keyCacheEntry).keys, true
+}
+
+// Start records that the keys for key are being looked up. It returns false
+// if they are already stored or being looked up, so that each filter is only
+// looked up once.
+func (c *keyCache) Start(key keyCacheKey) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok || c.pending[key] {
+		return false
+	}
+
+	c.pending[key] = true
+	return true
+}
+
+// Put stores the keys looked up for key, evicting the least recently used
+// keys if the cache is full.
+func (c *keyCache) Put(key keyCacheKey, keys map[string]int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, key)
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*keyCacheEntry).keys = keys
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&keyCacheEntry{key: key, keys: keys})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*keyCacheEntry).key)
+	}
+}
+
+// keyEntries returns the autocomplete entries for the keys which complete
+// the key being typed in c, with the text of filter before and after it, and
+// the offsets of the characters of each entry which match the key. Keys which
+// match equally well are ordered by the number of objects which have them.
+func keyEntries(filter string, cursor int, c completionContext, counts map[string]int, mode AutocompleteMatch) (entries []string, highlights [][]int) {
+	keys := slices.Sorted(maps.Keys(counts))
+	for _, m := range mode.matchAll(keys, c.word, func(i int) int { return counts[keys[i]] }) {
+		key := keys[m.index]
+		quoted := c.kind == completeQuotedKey || !pathIdentifier.MatchString(key)
+
+		offsets := make([]int, len(m.positions))
+		for i, pos := range m.positions {
+			offsets[i] = c.start + pos
+			if quoted {
+				// The offset of the character in the quoted key,
+				// whose characters before it may be escaped
+				offsets[i] = c.start + len(quoteJSON(key[:pos])) - 1
+			}
+		}
+
+		if quoted {
+			key = quoteJSON(key)
+		}
+
+		entries = append(entries, filter[:c.start]+key+filter[cursor:])
+		highlights = append(highlights, offsets)
+	}
+
+	return entries, highlights
+}
+
+// inputCursor keeps track of the cursor of an input, which is needed to
+// complete the text before it. tview does not expose the cursor of an
+// InputField, so the keys the input handles are passed to Key, which moves
+// the cursor as the input does, and Offset moves it along with each edit of
+// the text. A click leaves the cursor unknown until the text is next edited;
+// until then, it is taken to be at the end of the text. It is only used from
+// the event loop.
+type inputCursor struct {
+	text   string
+	offset int
+
+	// anchor is where the selection started, which is the offset when
+	// nothing is selected
+	anchor int
+	known  bool
+
+	// forward is set when the key being handled deletes the text after
+	// the cursor, rather than the text before it or the selection
+	forward bool
+}
+
+// newInputCursor returns the cursor of an input whose text is text, which is
+// at the end of it.
+func newInputCursor(text string) *inputCursor {
+	c := &inputCursor{}
+	c.Set(text)
+	return c
+}
+
+// Set records that the text of the input is set to text, which moves the
+// cursor to the end of it.
+func (c *inputCursor) Set(text string) {
+	c.text, c.offset, c.anchor, c.known, c.forward = text, len(text), len(text), true, false
+}
+
+// Forget records that the cursor was moved to an unknown place, e.g. by
+// clicking in the input.
+func (c *inputCursor) Forget() {
+	c.known = false
+}
+
+// Offset returns the cursor in text, the text of the input. It is called
+// each time the text changes, so that the cursor is moved along with the
+// edit.
+func (c *inputCursor) Offset(text string) int {
+	if text != c.text {
+		switch {
+		case !c.known:
+			c.offset = editedOffset(c.text, text)
+		case c.forward && c.anchor == c.offset:
+			// The text after the cursor was deleted
+		default:
+			// The selection, or the text before the cursor, was
+			// replaced, e.g. by typing
+			c.offset = max(c.offset, c.anchor) + len(text) - len(c.text)
+		}
+
+		c.offset = min(max(c.offset, 0), len(text))
+		for c.offset < len(text) && !utf8.RuneStart(text[c.offset]) {
+			c.offset++
+		}
+
+		c.text, c.anchor, c.known, c.forward = text, c.offset, true, false
+	}
+
+	if !c.known {
+		return len(text)
+	}
+
+	return c.offset
+}
+
+// Key moves the cursor as the input does when it handles event. text is the
+// text of the input before it handles the event.
+func (c *inputCursor) Key(event *tcell.EventKey, text string) {
+	offset := c.Offset(text)
+	c.forward = false
+
+	mods := event.Modifiers()
+	shift := mods&tcell.ModShift != 0
+	switch event.Key() {
+	case tcell.KeyLeft, tcell.KeyRight:
+		if mods&tcell.ModAlt != 0 || !c.known {
+			return
+		}
+
+		left := event.Key() == tcell.KeyLeft
+		switch {
+		case !shift && c.anchor != offset:
+			// Moving collapses the selection to its start or end
+			if left {
+				offset = min(offset, c.anchor)
+			} else {
+				offset = max(offset, c.anchor)
+			}
+		case mods&(tcell.ModCtrl|tcell.ModMeta) != 0 && left:
+			offset = wordStart(text, offset)
+		case mods&(tcell.ModCtrl|tcell.ModMeta) != 0:
+			offset = wordEnd(text, offset, shift)
+		case left:
+			offset = previousCluster(text, offset)
+		default:
+			offset = nextCluster(text, offset)
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA, tcell.KeyCtrlB:
+		offset = 0
+		c.known = true
+	case tcell.KeyEnd, tcell.KeyCtrlE, tcell.KeyCtrlF:
+		offset = len(text)
+		c.known = true
+	case tcell.KeyUp, tcell.KeyPgUp, tcell.KeyPgDn:
+		// These move the cursor to the start or the end of the text,
+		// unless the autocomplete list is open, which they navigate
+		// instead
+		c.known = false
+		return
+	case tcell.KeyDelete, tcell.KeyCtrlD:
+		c.forward = offset < len(text)
+		return
+	case tcell.KeyCtrlK:
+		// The text after the cursor is deleted, not the selection,
+		// which is cleared
+		c.anchor = offset
+		c.forward = offset < len(text)
+		return
+	case tcell.KeyCtrlU:
+		// The whole text is deleted
+		c.offset, c.anchor, c.forward = 0, 0, text != ""
+		return
+	case tcell.KeyCtrlW, tcell.KeyCtrlQ:
+		// The word before the cursor is deleted, or the selection
+		// copied, and the selection is cleared
+		c.anchor = offset
+		return
+	case tcell.KeyCtrlZ, tcell.KeyCtrlY:
+		// Undoing an edit moves the cursor back to where it was made
+		c.known = false
+		return
+	case tcell.KeyCtrlL:
+		// Everything is selected, with the cursor at the end
+		c.offset, c.anchor = len(text), 0
+		return
+	case tcell.KeyRune:
+		if mods&tcell.ModAlt == 0 || !c.known {
+			return
+		}
+
+		switch event.Rune() {
+		case 'f':
+			offset = wordEnd(text, offset, shift)
+		case 'b':
+			offset = wordStart(text, offset)
+		default:
+			return
+		}
+	default:
+		return
+	}
+
+	c.offset = offset
+	if !shift {
+		c.anchor = offset
+	}
+}
+
+// Move moves the cursor of input to offset by pressing the arrow keys.
+func (c *inputCursor) Move(input *tview.InputField, offset int) {
+	text := input.GetText()
+	from := c.Offset(text)
+	if !c.known || c.anchor != from || offset < 0 || offset > len(text) {
+		return
+	}
+
+	key, n := tcell.KeyLeft, uniseg.GraphemeClusterCount(text[min(offset, from):from])
+	if offset > from {
+		key, n = tcell.KeyRight, uniseg.GraphemeClusterCount(text[from:offset])
+	}
+
+	handler := input.InputHandler()
+	for range n {
+		handler(tcell.NewEventKey(key, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	c.offset, c.anchor = offset, offset
+}
+
+// previousCluster returns the start of the grapheme cluster before offset in
+// text.
+func previousCluster(text string, offset int) int {
+	previous := 0
+	state := -1
+	for pos := 0; pos < offset; {
+		var cluster string
+		previous = pos
+		cluster, _, _, state = uniseg.StepString(text[pos:], state)
+		pos += len(cluster)
+	}
+
+	return previous
+}
+
+// nextCluster returns the end of the grapheme cluster at offset in text.
+func nextCluster(text string, offset int) int {
+	if offset >= len(text) {
+		return len(text)
+	}
+
+	cluster, _, _, _ := uniseg.StepString(text[offset:], -1)
+	return offset + len(cluster)
+}
+
+// isWordRune reports whether r is part of a word, rather than space or
+// punctuation, when the cursor is moved by words.
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsPunct(r)
+}
+
+// wordStart returns the start of the word before offset in text, where the
+// input moves the cursor when it is moved one word left.
+func wordStart(text string, offset int) int {
+	start := 0
+	state, boundaries := -1, 0
+	inWord := false
+	for pos := 0; pos < offset; {
+		previous := boundaries
+		var cluster string
+		cluster, _, boundaries, state = uniseg.StepString(text[pos:], state)
+		r, _ := utf8.DecodeRuneInString(cluster)
+		if previous&uniseg.MaskWord != 0 {
+			if pos+len(cluster) != offset && !inWord && isWordRune(r) {
+				start = pos
+			}
+
+			inWord = false
+		}
+
+		if isWordRune(r) {
+			inWord = true
+		}
+
+		pos += len(cluster)
+	}
+
+	return start
+}
+
+// wordEnd returns where the input moves the cursor at offset in text when it
+// is moved one word right: before the last character of the next word, or
+// after it when the selection is extended.
+func wordEnd(text string, offset int, after bool) int {
+	state := -1
+	inWord := false
+	for pos := 0; pos < len(text); {
+		var cluster string
+		var boundaries int
+		cluster, _, boundaries, state = uniseg.StepString(text[pos:], state)
+		start := pos
+		pos += len(cluster)
+
+		// The cluster at the cursor is skipped
+		if start <= offset {
+			continue
+		}
+
+		r, _ := utf8.DecodeRuneInString(cluster)
+		if isWordRune(r) {
+			inWord = true
+		}
+
+		if inWord && boundaries&uniseg.MaskWord != 0 {
+			if after {
+				return pos
+			}
+
+			return start
+		}
+	}
+
+	return len(text)
+}
+
+// editedOffset returns where the cursor is likely to be once before is edited
+// to after, when nothing is known about where the edit was made: at the end
+// of the text which replaced the text which changed.
+func editedOffset(before, after string) int {
+	prefix := 0
+	for prefix < min(len(before), len(after)) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < min(len(before), len(after))-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	return len(after) - suffix
+}
+
+// completionList mirrors the autocomplete list of the filter input. It holds
+// the entries of the list, the entries as shown in the list, with the
+// characters which match the word being completed underlined, the text after
+// the cursor which each entry keeps and the hint for each entry, which is
+// drawn in a popup next to the list.
+type completionList struct {
+	mu      sync.Mutex
+	view    *tview.TextView
+	entries []string
+	items   []string
+	hints   []string
+	suffix  string
+}
+
+func newCompletionList() *completionList {
+	c := &completionList{view: tview.NewTextView()}
+	c.view.SetDynamicColors(true).SetWrap(false).SetBackgroundColor(tcell.ColorBlack)
+	return c
+}
+
+// Set sets the entries of the autocomplete list, the hint and the offsets of
+// the highlighted characters of each of them and the text after the cursor
+// which they end with. It returns the entries as they are shown in the list.
+// The popup is hidden when there are no hints.
+func (c *completionList) Set(entries, hints []string, highlights [][]int, suffix string) []string {
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		var offsets []int
+		if i < len(highlights) {
+			offsets = highlights[i]
+		}
+
+		items[i] = styleEntry(entry, offsets)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries, c.items, c.hints, c.suffix = entries, items, hints, suffix
+	return items
+}
+
+// styleEntry returns entry as it is shown in the autocomplete list, with the
+// characters at offsets underlined and the rest of the text escaped.
+func styleEntry(entry string, offsets []int) string {
+	var b strings.Builder
+	written := 0
+	for i := 0; i < len(offsets); {
+		// Consecutive characters are underlined together
+		start := offsets[i]
+		end := start
+		for ; i < len(offsets) && offsets[i] == end; i++ {
+			_, size := utf8.DecodeRuneInString(entry[end:])
+			end += size
+		}
+
+		b.WriteString(tview.Escape(entry[written:start]))
+		b.WriteString("[::u]" + tview.Escape(entry[start:end]) + "[::-]")
+		written = end
+	}
+
+	b.WriteString(tview.Escape(entry[written:]))
+	return b.String()
+}
+
+// Clear forgets the entries until the autocomplete list is filled again.
+func (c *completionList) Clear() {
+	c.Set(nil, nil, nil, "")
+}
+
+// Hide hides the popup when the autocomplete list is closed. The entries
+// are kept, since the list is closed by selecting one of them.
+func (c *completionList) Hide() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hints = nil
+}
+
+// Entry returns the entry at index in the autocomplete list, without the
+// style tags it is shown with.
+func (c *completionList) Entry(index int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index < 0 || index >= len(c.entries) {
+		return "", false
+	}
+
+	return c.entries[index], true
+}
+
+// Cursor returns where the cursor belongs when the text of the input is set
+// to text. Selecting an entry moves the cursor to the end of the text, but
+// it belongs before the text which was after it when the entry completes a
+// word in the middle of the filter.
+func (c *completionList) Cursor(text string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.suffix == "" || !slices.Contains(c.entries, text) {
+		return 0, false
+	}
+
+	return len(text) - len(c.suffix), true
+}
+
+// selected returns the index of the entry selected in the autocomplete list
+// of an input whose text is text. The input's text is set to an entry when
+// it is selected; otherwise the list selects the first entry which, as it is
+// shown, starts with the text.
+func (c *completionList) selected(text string) int {
+	if i := slices.Index(c.entries, text); i >= 0 {
+		return i
+	}
+
+	for i, item := range c.items {
+		if strings.HasPrefix(item, text) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// DrawHint draws the hint of the selected entry to the right of the
+// autocomplete list of input. The list is placed in the same way as
+// tview.InputField places it.
+func (c *completionList) DrawHint(screen tcell.Screen, input *tview.InputField) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.hints) == 0 || !input.HasFocus() {
+		return
+	}
+
+	x, y, _, _ := input.GetInnerRect()
+	x += tview.TaggedStringWidth(input.GetLabel())
+
+	lheight := len(c.entries)
+	lwidth := 0
+	for _, item := range c.items {
+		lwidth = max(lwidth, tview.TaggedStringWidth(item))
+	}
+
+	ly := y + 1
+	swidth, sheight := screen.Size()
+	if ly+lheight >= sheight && ly-2 > lheight-ly {
+		ly = max(y-lheight, 0)
+	}
+
+	if ly+lheight >= sheight {
+		lheight = sheight - ly
+	}
+
+	if lheight <= 0 {
+		return
+	}
+
+	selected := c.selected(input.GetText())
+	hint := " " + c.hints[selected] + " "
+	hx := x + lwidth + 1
+	width := min(tview.TaggedStringWidth(hint), swidth-hx)
+	if width <= 0 {
+		return
+	}
+
+	c.view.SetText(hint)
+	c.view.SetRect(hx, ly+min(selected, lheight-1), width, 1)
+	c.view.Draw(screen)
+}
@@ -0,0 +1,375 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func TestParseBuiltinSummary(t *testing.T) {
+	builtins := parseBuiltinSummary("# comment\nselect(f)\tSelects.\n\nlength\tThe length.\nrange(from; upto; by)\tCounts.\n")
+	assert.Equal(t, map[string]builtin{
+		"select/1": {name: "select", arity: 1, signature: "select(f)", description: "Selects."},
+		"length/0": {name: "length", arity: 0, signature: "length", description: "The length."},
+		"range/3":  {name: "range", arity: 3, signature: "range(from; upto; by)", description: "Counts."},
+	}, builtins)
+
+	// Every function in the summary of the manual has a description
+	for key, b := range documentedBuiltins {
+		assert.NotEmpty(t, b.description, key)
+	}
+}
+
+func TestParseBuiltins(t *testing.T) {
+	builtins := parseBuiltins([]string{"splits/2", "_modify/2", "splits/1", "select/1", "select/1", "frobnicate/0", "bad"})
+	require.Len(t, builtins, 4)
+
+	assert.Equal(t, "frobnicate/0", builtins[0].Signature())
+	assert.Equal(t, "select(f)", builtins[1].Signature())
+	assert.Equal(t, "splits(re)", builtins[2].Signature())
+	assert.Equal(t, "splits(re; flags)", builtins[3].Signature())
+}
+
+func TestLoadBuiltins(t *testing.T) {
+	builtins, err := loadBuiltins(context.Background(), options.Options{Engine: "gojq", Slurp: true, RawOutput: true})
+	require.NoError(t, err)
+
+	var keys []string
+	for _, b := range builtins {
+		keys = append(keys, b.key())
+	}
+
+	assert.Contains(t, keys, "select/1")
+	assert.Contains(t, keys, "to_entries/0")
+}
+
+func TestBuiltinSetComplete(t *testing.T) {
+	var builtins builtinSet
+	builtins.Load(context.Background(), options.Options{Engine: "exec", JQCommand: "ijq-nonexistent-jq"})
+
+	// The builtins from the manual are used when jq cannot list them
+	matches := builtins.Complete("to_en")
+	require.Len(t, matches, 1)
+	assert.Equal(t, "to_entries", matches[0].name)
+
+	assert.Empty(t, builtins.Complete("nonexistent"))
+}
+
+func TestDecodeKeys(t *testing.T) {
+	keys, err := decodeKeys([]byte(`{"b":1,"a":2}` + "\n" + `{"c":1,"a":1}` + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 3, "b": 1, "c": 1}, keys)
+
+	_, err = decodeKeys([]byte(`{"a":"b"}`))
+	assert.Error(t, err)
+}
+
+func TestKeysFilter(t *testing.T) {
+	doc := Document{
+		input:     `{"items":[{"id":1,"app name":"a"},{"id":2,"tags":[]}],"count":2}`,
+		filter:    keysFilter("", ".items | .[]"),
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		ctx:       context.Background(),
+	}
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+
+	keys, err := decodeKeys(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"app name": 1, "id": 2, "tags": 1}, keys)
+
+	// Values which are not objects and errors are skipped
+	buf.Reset()
+	_, err = doc.WithFilter(keysFilter("", ".count | .[]")).WriteTo(&buf)
+	require.NoError(t, err)
+	keys, err = decodeKeys(buf.Bytes())
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestKeyOptions(t *testing.T) {
+	opts := options.Options{Engine: "gojq"}
+	key := keyOptions(opts)
+
+	// Options which only change how the output is written share keys
+	opts.CompactOutput = true
+	opts.SortKeys = true
+	assert.Equal(t, key, keyOptions(opts))
+
+	for _, toggle := range []func(*options.Options){
+		func(o *options.Options) { o.Slurp = true },
+		func(o *options.Options) { o.RawInput = true },
+		func(o *options.Options) { o.NullInput = true },
+		func(o *options.Options) { o.Stream = true },
+		func(o *options.Options) { o.Engine = "exec" },
+		func(o *options.Options) { o.InputFormat = "yaml" },
+		func(o *options.Options) { o.StringArgs = options.StringArgs{{Name: "a", Value: "b"}} },
+	} {
+		changed := opts
+		toggle(&changed)
+		assert.NotEqual(t, key, keyOptions(changed))
+	}
+}
+
+func TestKeyCache(t *testing.T) {
+	c := newKeyCache(2)
+	a := keyCacheKey{input: ".a"}
+	b := keyCacheKey{input: ".b"}
+
+	_, ok := c.Get(a)
+	assert.False(t, ok)
+
+	// Each filter is looked up once
+	assert.True(t, c.Start(a))
+	assert.False(t, c.Start(a))
+	c.Put(a, map[string]int{"x": 1})
+	assert.False(t, c.Start(a))
+
+	keys, ok := c.Get(a)
+	require.True(t, ok)
+	assert.Equal(t, map[string]int{"x": 1}, keys)
+
+	// Keys of other options and generations are stored separately
+	_, ok = c.Get(keyCacheKey{input: ".a", options: "-s"})
+	assert.False(t, ok)
+	_, ok = c.Get(keyCacheKey{input: ".a", generation: 1})
+	assert.False(t, ok)
+
+	// The least recently used filter is evicted
+	c.Put(b, map[string]int{"y": 1})
+	c.Get(a)
+	c.Put(keyCacheKey{input: ".c"}, nil)
+
+	_, ok = c.Get(b)
+	assert.False(t, ok)
+	_, ok = c.Get(a)
+	assert.True(t, ok)
+
+	// A failed lookup is stored, without keys
+	keys, ok = c.Get(keyCacheKey{input: ".c"})
+	assert.True(t, ok)
+	assert.Empty(t, keys)
+}
+
+func TestKeyEntries(t *testing.T) {
+	keys := map[string]int{"app name": 1, "id": 1, "items": 1}
+
+	filter := ".a | .i | length"
+	c := completionContextAt(filter, 7)
+	entries, highlights := keyEntries(filter, 7, c, keys, matchPrefix)
+	assert.Equal(t, []string{".a | .id | length", ".a | .items | length"}, entries)
+	assert.Equal(t, [][]int{{6}, {6}}, highlights)
+
+	filter = ".a | ."
+	c = completionContextAt(filter, len(filter))
+	entries, _ = keyEntries(filter, len(filter), c, keys, matchPrefix)
+	assert.Equal(t, []string{`.a | ."app name"`, ".a | .id", ".a | .items"}, entries)
+
+	filter = `."app`
+	c = completionContextAt(filter, len(filter))
+	entries, highlights = keyEntries(filter, len(filter), c, keys, matchPrefix)
+	assert.Equal(t, []string{`."app name"`}, entries)
+	assert.Equal(t, [][]int{{2, 3, 4}}, highlights)
+
+	// Keys which match equally well are ordered by how many objects have
+	// them
+	keys = map[string]int{"user_id": 1, "team_id": 5, "identity": 2, "name": 9}
+	filter = ".id"
+	c = completionContextAt(filter, len(filter))
+	entries, highlights = keyEntries(filter, len(filter), c, keys, matchFuzzy)
+	assert.Equal(t, []string{".identity", ".team_id", ".user_id"}, entries)
+	assert.Equal(t, [][]int{{1, 2}, {6, 7}, {6, 7}}, highlights)
+	entries, _ = keyEntries(filter, len(filter), c, keys, matchPrefix)
+	assert.Equal(t, []string{".identity"}, entries)
+
+	// Offsets account for the characters escaped in quoted keys
+	keys = map[string]int{`a"b`: 1}
+	filter = ".b"
+	c = completionContextAt(filter, len(filter))
+	entries, highlights = keyEntries(filter, len(filter), c, keys, matchSubstring)
+	assert.Equal(t, []string{`."a\"b"`}, entries)
+	assert.Equal(t, [][]int{{5}}, highlights)
+}
+
+func TestInputCursor(t *testing.T) {
+	key := func(key tcell.Key, mods tcell.ModMask) *tcell.EventKey {
+		return tcell.NewEventKey(key, 0, mods)
+	}
+	char := func(ch rune, mods tcell.ModMask) *tcell.EventKey {
+		return tcell.NewEventKey(tcell.KeyRune, ch, mods)
+	}
+
+	screen := tcell.NewSimulationScreen("")
+	require.NoError(t, screen.Init())
+	defer screen.Fini()
+
+	for _, keys := range [][]*tcell.EventKey{
+		{},
+		{key(tcell.KeyLeft, 0), key(tcell.KeyLeft, 0)},
+		{key(tcell.KeyHome, 0), key(tcell.KeyRight, 0), key(tcell.KeyRight, 0)},
+		{key(tcell.KeyCtrlA, 0), key(tcell.KeyRight, tcell.ModCtrl), key(tcell.KeyRight, tcell.ModCtrl)},
+		{key(tcell.KeyLeft, tcell.ModCtrl), key(tcell.KeyLeft, tcell.ModCtrl), char('x', 0)},
+		{char('b', tcell.ModAlt), char('b', tcell.ModAlt), char('f', tcell.ModAlt)},
+		{key(tcell.KeyLeft, 0), key(tcell.KeyBackspace2, 0), key(tcell.KeyBackspace2, 0)},
+		{key(tcell.KeyCtrlB, 0), key(tcell.KeyDelete, 0), key(tcell.KeyRight, 0), key(tcell.KeyCtrlD, 0)},
+		{key(tcell.KeyLeft, tcell.ModShift), key(tcell.KeyLeft, tcell.ModShift), key(tcell.KeyLeft, 0)},
+		{key(tcell.KeyLeft, tcell.ModShift|tcell.ModCtrl), char('x', 0)},
+		{key(tcell.KeyHome, 0), key(tcell.KeyRight, tcell.ModShift), key(tcell.KeyRight, tcell.ModShift), key(tcell.KeyBackspace2, 0)},
+		{key(tcell.KeyCtrlL, 0), key(tcell.KeyRight, 0)},
+		{key(tcell.KeyCtrlL, 0), char('x', 0)},
+		{key(tcell.KeyLeft, tcell.ModCtrl), key(tcell.KeyCtrlW, 0), key(tcell.KeyRight, 0)},
+		{key(tcell.KeyLeft, tcell.ModCtrl), key(tcell.KeyLeft, tcell.ModCtrl), key(tcell.KeyCtrlK, 0)},
+		{key(tcell.KeyLeft, 0), key(tcell.KeyCtrlU, 0), char('x', 0)},
+		{key(tcell.KeyCtrlE, 0), key(tcell.KeyBackspace2, tcell.ModAlt)},
+	} {
+		const text = `.a["é x"] | .b.c  `
+		input := tview.NewInputField()
+		input.SetRect(0, 0, 80, 1)
+		input.SetText(text)
+
+		c := newInputCursor(text)
+		input.SetChangedFunc(func(text string) {
+			c.Offset(text)
+		})
+
+		for _, event := range keys {
+			input.Draw(screen)
+			c.Key(event, input.GetText())
+			input.InputHandler()(event, func(tview.Primitive) {})
+		}
+
+		// The cursor is where a character typed next is inserted
+		offset := c.Offset(input.GetText())
+		input.InputHandler()(char('@', 0), func(tview.Primitive) {})
+		assert.Equal(t, strings.Index(input.GetText(), "@"), offset, "%v: %q", keys, input.GetText())
+	}
+}
+
+func TestInputCursorMove(t *testing.T) {
+	input := tview.NewInputField()
+	input.SetText(`.a["é x"] | .b`)
+	c := newInputCursor(input.GetText())
+
+	c.Move(input, 4)
+	assert.Equal(t, 4, c.Offset(input.GetText()))
+	c.Move(input, 7)
+	assert.Equal(t, 7, c.Offset(input.GetText()))
+
+	input.InputHandler()(tcell.NewEventKey(tcell.KeyRune, '@', tcell.ModNone), func(tview.Primitive) {})
+	assert.Equal(t, `.a["é @x"] | .b`, input.GetText())
+}
+
+func TestEditedOffset(t *testing.T) {
+	for _, tt := range []struct {
+		before string
+		after  string
+		edited int
+	}{
+		{".a", ".ab", 3},
+		{".a | .b", ".ab | .b", 3},
+		{".a | .b", ".a | ", 5},
+		{".n | .b", `."first name" | .b`, 13},
+		{".é", ".è", 3},
+		{".a", "", 0},
+	} {
+		assert.Equal(t, tt.edited, editedOffset(tt.before, tt.after), "%q %q", tt.before, tt.after)
+	}
+}
+
+func TestCompletionListCursor(t *testing.T) {
+	c := newCompletionList()
+	c.Set([]string{".id | length", ".items | length"}, nil, nil, " | length")
+
+	pos, ok := c.Cursor(".items | length")
+	require.True(t, ok)
+	assert.Equal(t, len(".items"), pos)
+
+	_, ok = c.Cursor(".item | length")
+	assert.False(t, ok)
+
+	// Hiding the list keeps the entries which are selected by closing it
+	c.Hide()
+	_, ok = c.Cursor(".id | length")
+	assert.True(t, ok)
+
+	c.Clear()
+	_, ok = c.Cursor(".id | length")
+	assert.False(t, ok)
+}
+
+func TestCompletionListSelected(t *testing.T) {
+	c := newCompletionList()
+	c.Set([]string{"sort", "sort_by", "split"}, []string{"a", "b", "c"}, nil, "")
+
+	assert.Equal(t, 0, c.selected("so"))
+	assert.Equal(t, 1, c.selected("sort_by"))
+	assert.Equal(t, 2, c.selected("spl"))
+	assert.Equal(t, 0, c.selected("x"))
+
+	// The list matches the text against the entries as it shows them
+	c.Set([]string{"sort", "split"}, nil, [][]int{{0}, nil}, "")
+	assert.Equal(t, 1, c.selected("s"))
+}
+
+func TestCompletionListEntry(t *testing.T) {
+	c := newCompletionList()
+	items := c.Set([]string{".items[0]", ".id"}, nil, [][]int{{1, 2}, {1}}, "")
+	assert.Equal(t, []string{".[::u]it[::-]ems[0[]", ".[::u]i[::-]d"}, items)
+
+	entry, ok := c.Entry(0)
+	require.True(t, ok)
+	assert.Equal(t, ".items[0]", entry)
+
+	_, ok = c.Entry(2)
+	assert.False(t, ok)
+}
+
+func TestStyleEntry(t *testing.T) {
+	for _, tt := range []struct {
+		entry   string
+		offsets []int
+		styled  string
+	}{
+		{".name", nil, ".name"},
+		{".first_name", []int{1, 7, 8}, ".[::u]f[::-]irst_[::u]na[::-]me"},
+		{".ünï", []int{1, 3}, ".[::u]ün[::-]ï"},
+		// The text around the underlined characters is escaped
+		{`.["a[b]"]`, []int{5}, `.["a[[::u]b[::-]]"]`},
+		{`.["[ab]"]`, []int{3}, `.["[::u][[::-]ab]"]`},
+		{`.["[ab]"]`, []int{2}, `.[[::u]"[::-][ab[]"]`},
+	} {
+		styled := styleEntry(tt.entry, tt.offsets)
+		assert.Equal(t, tt.styled, styled, "%q %v", tt.entry, tt.offsets)
+
+		// The list shows the entry itself
+		view := tview.NewTextView().SetDynamicColors(true).SetText(styled)
+		assert.Equal(t, tt.entry, view.GetText(true), "%q %v", tt.entry, tt.offsets)
+	}
+}
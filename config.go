@@ -20,7 +20,15 @@ type Config struct {
 	JQCommand     options.JQCommand     `scfg:"jq-bin"`
 	HideInputPane options.HideInputPane `scfg:"hide-input-pane"`
 	LibraryPaths  options.LibraryPaths  `scfg:"library-paths"`
+	Engine        options.Engine        `scfg:"engine"`
 	Keymap        Keymap                `scfg:"keymaps"`
+
+	// How completions are matched against the word being typed
+	AutocompleteMatch AutocompleteMatch `scfg:"autocomplete-match"`
+
+	// The command the text to copy is written to when it cannot be
+	// copied through the terminal
+	ClipboardCommand []string `scfg:"clipboard-command"`
 }
 
 func DefaultConfig() Config {
@@ -40,7 +48,10 @@ func DefaultConfig() Config {
 		HistoryFile:   historyFile,
 		JQCommand:     "jq",
 		HideInputPane: false,
+		Engine:        "exec",
 		Keymap:        DefaultKeymap(),
+
+		AutocompleteMatch: matchPrefix,
 	}
 }
 
@@ -43,6 +43,8 @@ func TestLoadConfigMissingFile(t *testing.T) {
 	assert.Equal(t, filepath.Join(tmp, "ijq", "history"), string(cfg.HistoryFile))
 	assert.Equal(t, "jq", string(cfg.JQCommand))
 	assert.False(t, bool(cfg.HideInputPane))
+	assert.Equal(t, options.Engine("exec"), cfg.Engine)
+	assert.Equal(t, matchPrefix, cfg.AutocompleteMatch)
 }
 
 func TestLoadConfig(t *testing.T) {
@@ -53,6 +55,9 @@ func TestLoadConfig(t *testing.T) {
 jq-bin /usr/local/bin/jq
 hide-input-pane true
 library-paths /tmp/modules /opt/jq/modules
+engine gojq
+autocomplete-match fuzzy
+clipboard-command xclip -selection clipboard
 keymaps {
 	toggle-input-pane Ctrl-T
 	save-filter-history Alt+h
@@ -70,6 +75,9 @@ keymaps {
 	assert.Equal(t, "/usr/local/bin/jq", string(cfg.JQCommand))
 	assert.True(t, bool(cfg.HideInputPane))
 	assert.Equal(t, options.LibraryPaths{"/tmp/modules", "/opt/jq/modules"}, cfg.LibraryPaths)
+	assert.Equal(t, matchFuzzy, cfg.AutocompleteMatch)
+	assert.Equal(t, options.Engine("gojq"), cfg.Engine)
+	assert.Equal(t, []string{"xclip", "-selection", "clipboard"}, cfg.ClipboardCommand)
 
 	assert.Equal(t, KeyBindings{{key: tcell.KeyCtrlT}}, cfg.Keymap.ToggleInputPane)
 	assert.Equal(t, KeyBindings{{key: tcell.KeyRune, rune: 'h', mods: tcell.ModAlt}}, cfg.Keymap.SaveFilterHistory)
@@ -88,3 +96,27 @@ func TestLoadConfigInvalidFile(t *testing.T) {
 	_, err = NewConfig(path)
 	assert.Error(t, err)
 }
+
+func TestLoadConfigInvalidEngine(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config")
+
+	err := os.WriteFile(path, []byte("engine jaq\n"), 0o644)
+	assert.NoError(t, err)
+
+	_, err = NewConfig(path)
+	assert.Error(t, err)
+}
+
+func TestLoadConfigInvalidAutocompleteMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config")
+
+	err := os.WriteFile(path, []byte("autocomplete-match exact\n"), 0o644)
+	assert.NoError(t, err)
+
+	_, err = NewConfig(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid value "exact"`)
+	}
+}
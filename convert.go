@@ -0,0 +1,850 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/yaml.v3"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// detectInputFormat returns the input format implied by the extension of the
+// given file name, or "json" if the extension is not recognized. Extensions of
+// compressed files are ignored.
+func detectInputFormat(name string) options.InputFormat {
+	name = strings.ToLower(name)
+	switch filepath.Ext(name) {
+	case ".gz", ".zst", ".bz2", ".xz":
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".csv":
+		return "csv"
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".xml":
+		return "xml"
+	default:
+		return "json"
+	}
+}
+
+// convertInput converts input in the given format into JSON text. Formats
+// which can hold several documents (YAML) or records (CSV and TSV) produce one
+// JSON value per line.
+func convertInput(input string, format options.InputFormat) (string, error) {
+	var (
+		values []any
+		err    error
+	)
+
+	switch format {
+	case "", "json":
+		return input, nil
+	case "yaml":
+		values, err = parseYAML(input)
+	case "toml":
+		values, err = parseTOML(input)
+	case "csv":
+		values, err = parseCSV(input, ',')
+	case "tsv":
+		values, err = parseCSV(input, '\t')
+	case "xml":
+		values, err = parseXML(input)
+	default:
+		return "", fmt.Errorf("unknown input format %q", format)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", format, err)
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return "", fmt.Errorf("%s: %w", format, err)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// orderedObject is a JSON object which keeps its keys in the order of the
+// source document.
+type orderedObject []objectEntry
+
+type objectEntry struct {
+	key   string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, entry := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		key, err := marshalJSON(entry.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := marshalJSON(entry.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// marshalJSON is like json.Marshal, but does not escape HTML characters.
+func marshalJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// objectBuilder builds an orderedObject whose members are set by key. It
+// keeps the index of each key, so that setting a member does not scan the
+// members before it.
+type objectBuilder struct {
+	obj   orderedObject
+	index map[string]int
+}
+
+// set replaces the value of key if it exists, and adds it otherwise.
+func (b *objectBuilder) set(key string, value any) {
+	if i, ok := b.index[key]; ok {
+		b.obj[i].value = value
+		return
+	}
+
+	if b.index == nil {
+		b.index = make(map[string]int)
+	}
+
+	b.index[key] = len(b.obj)
+	b.obj = append(b.obj, objectEntry{key, value})
+}
+
+func (b *objectBuilder) get(key string) (any, bool) {
+	i, ok := b.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	return b.obj[i].value, true
+}
+
+// sorted returns a copy of o with its members sorted by key.
+func (o orderedObject) sorted() orderedObject {
+	return slices.SortedFunc(slices.Values(o), func(a, b objectEntry) int {
+		return cmp.Compare(a.key, b.key)
+	})
+}
+
+func (o orderedObject) get(key string) (any, bool) {
+	for _, entry := range o {
+		if entry.key == key {
+			return entry.value, true
+		}
+	}
+
+	return nil, false
+}
+
+func parseYAML(input string) ([]any, error) {
+	var values []any
+
+	dec := yaml.NewDecoder(strings.NewReader(input))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		v, err := newYAMLConverter().value(doc.Content[0])
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// maxYAMLAliases is the number of aliases expanded in a YAML document, above
+// which it is rejected, so that aliases which refer to each other many times
+// over cannot expand without limit.
+const maxYAMLAliases = 10000
+
+// yamlConverter converts the nodes of a YAML document to values, expanding
+// the aliases in it.
+type yamlConverter struct {
+	// expanding holds the anchors whose aliases are being expanded, to
+	// detect anchors which contain aliases to themselves
+	expanding map[*yaml.Node]bool
+	aliases   int
+}
+
+func newYAMLConverter() *yamlConverter {
+	return &yamlConverter{expanding: make(map[*yaml.Node]bool)}
+}
+
+func (c *yamlConverter) value(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		if c.expanding[node.Alias] {
+			return nil, fmt.Errorf("line %d: alias *%s refers to itself", node.Line, node.Value)
+		}
+
+		if c.aliases++; c.aliases > maxYAMLAliases {
+			return nil, fmt.Errorf("line %d: document expands more than %d aliases", node.Line, maxYAMLAliases)
+		}
+
+		c.expanding[node.Alias] = true
+		defer delete(c.expanding, node.Alias)
+		return c.value(node.Alias)
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			v, err := c.value(child)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, v)
+		}
+
+		return values, nil
+	case yaml.MappingNode:
+		var obj objectBuilder
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			v, err := c.value(value)
+			if err != nil {
+				return nil, err
+			}
+
+			if key.Tag == "!!merge" {
+				// Merge keys copy the entries of the referenced
+				// mappings without overriding existing keys
+				merged, ok := v.(orderedObject)
+				if list, isList := v.([]any); isList {
+					merged, ok = orderedObject{}, true
+					for _, item := range list {
+						if m, isMap := item.(orderedObject); isMap {
+							merged = append(merged, m...)
+						}
+					}
+				}
+
+				if !ok {
+					return nil, fmt.Errorf("line %d: merge value is not a mapping", key.Line)
+				}
+
+				for _, entry := range merged {
+					if _, exists := obj.get(entry.key); !exists {
+						obj.set(entry.key, entry.value)
+					}
+				}
+
+				continue
+			}
+
+			var k any
+			if err := key.Decode(&k); err != nil {
+				return nil, err
+			}
+
+			obj.set(scalarString(k), v)
+		}
+
+		return obj.obj, nil
+	default:
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+
+		switch v := v.(type) {
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, nil
+			}
+		case time.Time:
+			return v.Format(time.RFC3339Nano), nil
+		}
+
+		return v, nil
+	}
+}
+
+// scalarString returns the string form of a scalar used as an object key.
+func scalarString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseTOML(input string) ([]any, error) {
+	var table map[string]any
+	if _, err := toml.Decode(input, &table); err != nil {
+		return nil, err
+	}
+
+	return []any{tomlValue(table)}, nil
+}
+
+func tomlValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = tomlValue(value)
+		}
+
+		return v
+	case []map[string]any:
+		values := make([]any, len(v))
+		for i, value := range v {
+			values[i] = tomlValue(value)
+		}
+
+		return values
+	case []any:
+		for i, value := range v {
+			v[i] = tomlValue(value)
+		}
+
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		// Local dates and times
+		return v.String()
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// parseCSV returns an object for each record after the header, keyed by the
+// column names in the header. Values are always strings.
+func parseCSV(input string, comma rune) ([]any, error) {
+	r := csv.NewReader(strings.NewReader(input))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = comma == '\t'
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var values []any
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		obj := objectBuilder{obj: make(orderedObject, 0, len(header))}
+		for i, name := range header {
+			if i < len(record) {
+				obj.set(name, record[i])
+			}
+		}
+
+		values = append(values, obj.obj)
+	}
+
+	return values, nil
+}
+
+// parseXML converts an XML document into a single JSON object. Attributes are
+// stored under keys prefixed with "@" and text content of elements which also
+// have attributes or children is stored under "#text". Repeated child
+// elements become arrays.
+func parseXML(input string) ([]any, error) {
+	dec := xml.NewDecoder(strings.NewReader(input))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("no root element")
+			}
+
+			return nil, err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := xmlElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+
+			return []any{orderedObject{{start.Name.Local, v}}}, nil
+		}
+	}
+}
+
+func xmlElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	var obj objectBuilder
+	for _, attr := range start.Attr {
+		obj.set("@"+attr.Name.Local, attr.Value)
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			v, err := xmlElement(dec, tok)
+			if err != nil {
+				return nil, err
+			}
+
+			name := tok.Name.Local
+			if existing, ok := obj.get(name); ok {
+				if list, ok := existing.([]any); ok {
+					obj.set(name, append(list, v))
+				} else {
+					obj.set(name, []any{existing, v})
+				}
+			} else {
+				obj.set(name, v)
+			}
+		case xml.CharData:
+			text.Write(tok)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(obj.obj) == 0 {
+				if s == "" {
+					return nil, nil
+				}
+
+				return s, nil
+			}
+
+			if s != "" {
+				obj.set("#text", s)
+			}
+
+			return obj.obj, nil
+		}
+	}
+}
+
+// convertOutput converts the JSON values in output into the given format.
+func convertOutput(output string, format options.OutputFormat) (string, error) {
+	if format == "" || format == "json" {
+		return output, nil
+	}
+
+	values, err := decodeOrderedValues(output)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "yaml":
+		return formatYAML(values)
+	case "csv":
+		return formatCSV(values)
+	case "tsv":
+		return formatTSV(values), nil
+	case "table":
+		return formatTable(values), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// decodeOrderedValues decodes a sequence of JSON values, keeping the order
+// of object keys.
+func decodeOrderedValues(input string) ([]any, error) {
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+
+	var values []any
+	for {
+		v, err := decodeOrdered(dec)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, objectEntry{key.(string), v})
+		}
+
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, v)
+		}
+
+		_, err := dec.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+func formatYAML(values []any) (string, error) {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	for _, v := range values {
+		if err := enc.Encode(yamlNode(v)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+func yamlNode(v any) *yaml.Node {
+	switch v := v.(type) {
+	case orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, entry := range v {
+			node.Content = append(node.Content, yamlNode(entry.key), yamlNode(entry.value))
+		}
+
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+
+		return node
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprint(v)}
+	}
+}
+
+// tabular returns the header and rows of a table built from values. A single
+// array is treated as the list of rows. If every row is an object the header
+// holds the keys of all rows in the order they first appear; otherwise there
+// is no header and each array becomes a row of cells.
+func tabular(values []any) ([]string, [][]string) {
+	if len(values) == 1 {
+		if arr, ok := values[0].([]any); ok {
+			values = arr
+		}
+	}
+
+	objects := len(values) > 0
+	for _, v := range values {
+		if _, ok := v.(orderedObject); !ok {
+			objects = false
+			break
+		}
+	}
+
+	var (
+		header []string
+		rows   [][]string
+	)
+
+	if objects {
+		for _, v := range values {
+			for _, entry := range v.(orderedObject) {
+				if !slices.Contains(header, entry.key) {
+					header = append(header, entry.key)
+				}
+			}
+		}
+
+		for _, v := range values {
+			row := make([]string, len(header))
+			for i, key := range header {
+				if cell, ok := v.(orderedObject).get(key); ok {
+					row[i] = cellString(cell)
+				}
+			}
+
+			rows = append(rows, row)
+		}
+
+		return header, rows
+	}
+
+	for _, v := range values {
+		arr, ok := v.([]any)
+		if !ok {
+			arr = []any{v}
+		}
+
+		row := make([]string, len(arr))
+		for i, cell := range arr {
+			row[i] = cellString(cell)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return nil, rows
+}
+
+// cellString formats a value as a table cell. Strings and numbers are
+// written as is and nested values as compact JSON.
+func cellString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		b, err := marshalJSON(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+
+		return string(b)
+	}
+}
+
+func formatCSV(values []any) (string, error) {
+	header, rows := tabular(values)
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if header != nil {
+		if err := w.Write(header); err != nil {
+			return "", err
+		}
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// tsvEscaper escapes cells in the same way as jq's @tsv.
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+func formatTSV(values []any) string {
+	header, rows := tabular(values)
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+
+			tsvEscaper.WriteString(&b, cell)
+		}
+
+		b.WriteByte('\n')
+	}
+
+	if header != nil {
+		writeRow(header)
+	}
+
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return b.String()
+}
+
+// formatTable formats values as a table with aligned columns. A header is
+// separated from the rows by a line of dashes.
+func formatTable(values []any) string {
+	header, rows := tabular(values)
+
+	all := rows
+	if header != nil {
+		all = append([][]string{header}, rows...)
+	}
+
+	var widths []int
+	for _, row := range all {
+		for i, cell := range row {
+			cell = tsvEscaper.Replace(cell)
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i, cell := range row {
+			cell = tsvEscaper.Replace(cell)
+			if i > 0 {
+				line.WriteString("  ")
+			}
+
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-runewidth.StringWidth(cell)))
+		}
+
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	if header != nil {
+		writeRow(header)
+
+		dashes := make([]string, len(header))
+		for i := range header {
+			dashes[i] = strings.Repeat("-", widths[i])
+		}
+
+		writeRow(dashes)
+	}
+
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return b.String()
+}
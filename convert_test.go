@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func TestDetectInputFormat(t *testing.T) {
+	tests := map[string]options.InputFormat{
+		"deployment.yaml": "yaml",
+		"values.YML":      "yaml",
+		"Cargo.toml":      "toml",
+		"export.csv":      "csv",
+		"export.tsv":      "tsv",
+		"feed.xml":        "xml",
+		"data.json":       "json",
+		"pods.yaml.gz":    "yaml",
+		"events.json.zst": "json",
+		"README":          "json",
+	}
+
+	for name, want := range tests {
+		assert.Equal(t, want, detectInputFormat(name), name)
+	}
+}
+
+func TestConvertInputJSON(t *testing.T) {
+	out, err := convertInput(`{"a": 1}`, "json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, out)
+}
+
+func TestConvertInputYAML(t *testing.T) {
+	input := `
+base: &base
+  image: nginx
+  port: 80
+---
+name: web
+enabled: true
+ratio: 0.5
+empty:
+tags: [a, "<b>"]
+1: one
+spec:
+  <<: *base
+  port: 8080
+`
+
+	out, err := convertInput(input, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `{"base":{"image":"nginx","port":80}}
+{"name":"web","enabled":true,"ratio":0.5,"empty":null,"tags":["a","<b>"],"1":"one","spec":{"image":"nginx","port":8080}}
+`, out)
+
+	_, err = convertInput("a: [", "yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "yaml:")
+}
+
+func TestConvertInputYAMLAliases(t *testing.T) {
+	// An anchor which contains an alias to itself is an error rather than
+	// an endless expansion
+	_, err := convertInput("a: &x\n  b: *x\n", "yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "alias *x refers to itself")
+
+	// Aliases may be used many times, but not so many that the document
+	// grows without limit
+	var laughs strings.Builder
+	laughs.WriteString("a: &a [x, x, x, x, x, x, x, x, x, x]\n")
+	for i, prev := range "abcdefgh" {
+		name := string(rune('b' + i))
+		fmt.Fprintf(&laughs, "%s: &%s [*%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c]\n", name, name, prev, prev, prev, prev, prev, prev, prev, prev, prev, prev)
+	}
+
+	_, err = convertInput(laughs.String(), "yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 10000 aliases")
+
+	out, err := convertInput("a: &x 1\nb: [*x, *x]\n", "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":[1,1]}`+"\n", out)
+}
+
+func TestConvertInputTOML(t *testing.T) {
+	input := `
+title = "example"
+
+[server]
+port = 8080
+started = 2026-01-02T03:04:05Z
+
+[[items]]
+name = "a"
+
+[[items]]
+name = "b"
+`
+
+	out, err := convertInput(input, "toml")
+	require.NoError(t, err)
+	assert.Equal(t, `{"items":[{"name":"a"},{"name":"b"}],"server":{"port":8080,"started":"2026-01-02T03:04:05Z"},"title":"example"}
+`, out)
+}
+
+func TestConvertInputCSV(t *testing.T) {
+	out, err := convertInput("name,age\nalice,30\n\"bob, jr\",4\n", "csv")
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"alice","age":"30"}
+{"name":"bob, jr","age":"4"}
+`, out)
+
+	out, err = convertInput("name\tage\nalice\t30\n", "tsv")
+	require.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"alice\",\"age\":\"30\"}\n", out)
+
+	out, err = convertInput("", "csv")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	// A repeated column keeps its first position and its last value
+	out, err = convertInput("a,b,a\n1,2,3\n", "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":\"3\",\"b\":\"2\"}\n", out)
+}
+
+func TestConvertInputXML(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<catalog id="1">
+  <book lang="en">Go</book>
+  <book>jq</book>
+  <empty/>
+  <note>hello</note>
+</catalog>`
+
+	out, err := convertInput(input, "xml")
+	require.NoError(t, err)
+	assert.Equal(t, `{"catalog":{"@id":"1","book":[{"@lang":"en","#text":"Go"},"jq"],"empty":null,"note":"hello"}}
+`, out)
+
+	_, err = convertInput("<a>", "xml")
+	assert.Error(t, err)
+}
+
+func TestConvertOutputYAML(t *testing.T) {
+	out, err := convertOutput(`{"b":1,"a":[true,null,"true",1.5]} "x"`, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "b: 1\na:\n  - true\n  - null\n  - \"true\"\n  - 1.5\n---\nx\n", out)
+}
+
+func TestConvertOutputCSV(t *testing.T) {
+	input := `[{"name":"a","tags":["x"]},{"name":"b, c","extra":null}]`
+
+	out, err := convertOutput(input, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, "name,tags,extra\na,\"[\"\"x\"\"]\",\n\"b, c\",,\n", out)
+
+	out, err = convertOutput(input, "tsv")
+	require.NoError(t, err)
+	assert.Equal(t, "name\ttags\textra\na\t[\"x\"]\t\nb, c\t\t\n", out)
+
+	out, err = convertOutput(`[1,"a\tb"] [2,"c"]`, "tsv")
+	require.NoError(t, err)
+	assert.Equal(t, "1\ta\\tb\n2\tc\n", out)
+}
+
+func TestConvertOutputTable(t *testing.T) {
+	out, err := convertOutput(`{"name":"alice","age":30} {"name":"bob","age":4}`, "table")
+	require.NoError(t, err)
+	assert.Equal(t, "name   age\n-----  ---\nalice  30\nbob    4\n", out)
+
+	out, err = convertOutput(`"a" "bc"`, "table")
+	require.NoError(t, err)
+	assert.Equal(t, "a\nbc\n", out)
+}
+
+func TestConvertOutputJSONIsUnchanged(t *testing.T) {
+	out, err := convertOutput("{\n  \"a\": 1\n}\n", "json")
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1\n}\n", out)
+}
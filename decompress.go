@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressionFormats lists the supported compression formats and the magic
+// bytes which start their streams.
+var compressionFormats = []struct {
+	name  string
+	match func(head []byte) bool
+	open  func(io.Reader) (io.Reader, error)
+}{
+	{"gzip", hasMagic(0x1f, 0x8b), func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	}},
+	{"zstd", hasMagic(0x28, 0xb5, 0x2f, 0xfd), func(r io.Reader) (io.Reader, error) {
+		// With a concurrency of 1 the stream is decoded synchronously,
+		// so the decoder does not need to be closed
+		return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	}},
+	{"bzip2", isBzip2, func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	}},
+	{"xz", hasMagic(0xfd, '7', 'z', 'X', 'Z', 0x00), func(r io.Reader) (io.Reader, error) {
+		return xz.NewReader(r)
+	}},
+}
+
+// hasMagic returns a function which reports whether a stream starts with
+// magic.
+func hasMagic(magic ...byte) func(head []byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(head, magic)
+	}
+}
+
+// isBzip2 reports whether a stream starts with a bzip2 header: BZh and the
+// block size from 1 to 9, followed by the magic of the first block or of the
+// end of an empty stream. Checking the whole header keeps text which happens
+// to start with BZh from being taken for bzip2.
+func isBzip2(head []byte) bool {
+	if len(head) < 10 || !bytes.HasPrefix(head, []byte("BZh")) || head[3] < '1' || head[3] > '9' {
+		return false
+	}
+
+	block := head[4:10]
+	return bytes.Equal(block, []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}) ||
+		bytes.Equal(block, []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90})
+}
+
+// decompress detects whether r holds compressed data from its first bytes
+// and, if it does, returns a reader for the decompressed data along with the
+// name of the compression format. Uncompressed data is returned as is with an
+// empty format name.
+func decompress(r io.Reader) (io.Reader, string, error) {
+	br := bufio.NewReader(r)
+
+	// Peek returns fewer bytes and an error for short inputs, which simply
+	// cannot match any of the longer magic numbers
+	head, _ := br.Peek(10)
+	for _, format := range compressionFormats {
+		if !format.match(head) {
+			continue
+		}
+
+		dr, err := format.open(br)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", format.name, err)
+		}
+
+		return dr, format.name, nil
+	}
+
+	return br, "", nil
+}
+
+// describeInput returns a description of the input read from the given
+// sources, naming the compression format of each compressed source. It
+// returns an empty string if no source was compressed.
+func describeInput(names []string, compressions []string) string {
+	compressed := false
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name
+		if compressions[i] != "" {
+			parts[i] = fmt.Sprintf("%s (%s)", name, compressions[i])
+			compressed = true
+		}
+	}
+
+	if !compressed {
+		return ""
+	}
+
+	return strings.Join(parts, ", ")
+}
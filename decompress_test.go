@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+const compressedInput = "{\"a\":1}\n"
+
+func compress(t *testing.T, format string) []byte {
+	t.Helper()
+
+	var (
+		b   bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+
+	switch format {
+	case "gzip":
+		w = gzip.NewWriter(&b)
+	case "zstd":
+		w, err = zstd.NewWriter(&b)
+	case "xz":
+		w, err = xz.NewWriter(&b)
+	case "bzip2":
+		// The standard library cannot write bzip2 streams
+		data, err := os.ReadFile("testdata/input.json.bz2")
+		require.NoError(t, err)
+		return data
+	}
+
+	require.NoError(t, err)
+	_, err = w.Write([]byte(compressedInput))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return b.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	for _, format := range []string{"gzip", "zstd", "bzip2", "xz"} {
+		r, compression, err := decompress(bytes.NewReader(compress(t, format)))
+		require.NoError(t, err, format)
+		assert.Equal(t, format, compression)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err, format)
+		assert.Equal(t, compressedInput, string(data), format)
+	}
+}
+
+func TestDecompressPassesThroughUncompressedInput(t *testing.T) {
+	// Text which starts like a bzip2 header is not taken for one
+	for _, input := range []string{"", "1", `{"a":1}`, "BZ", "BZh", "BZh is not bzip2\n", "BZh9 notes\n", "BZh91AY&SX"} {
+		r, compression, err := decompress(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Empty(t, compression)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, input, string(data))
+	}
+}
+
+func TestDecompressCorruptInput(t *testing.T) {
+	_, _, err := decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	assert.Error(t, err)
+}
+
+func TestDecompressEmptyBzip2(t *testing.T) {
+	// An empty bzip2 stream is a header followed by the end of the stream
+	empty := []byte{'B', 'Z', 'h', '9', 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x00, 0x00, 0x00, 0x00}
+	r, compression, err := decompress(bytes.NewReader(empty))
+	require.NoError(t, err)
+	assert.Equal(t, "bzip2", compression)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestReadTabsDescribesCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json.gz")
+	b := filepath.Join(dir, "b.json")
+	require.NoError(t, os.WriteFile(a, compress(t, "gzip"), 0o644))
+	require.NoError(t, os.WriteFile(b, []byte("2\n"), 0o644))
+
+	tabs, err := readTabs([]string{a, b})
+	require.NoError(t, err)
+
+	doc := Document{tabs: tabs}
+	doc.selectTab(0)
+	assert.Equal(t, compressedInput+"2\n", doc.input)
+	assert.Equal(t, a+" (gzip), "+b, doc.describeSource())
+}
@@ -0,0 +1,363 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rivo/tview"
+)
+
+// maxDiffCells limits the size of the table used to match the elements of
+// two arrays. Longer arrays are compared element by element.
+const maxDiffCells = 1 << 20
+
+// diffOp is the kind of a difference between two JSON values.
+type diffOp byte
+
+const (
+	diffAdded   diffOp = '+'
+	diffRemoved diffOp = '-'
+	diffChanged diffOp = '~'
+)
+
+// diffEntry is a single difference between two JSON values.
+type diffEntry struct {
+	op diffOp
+
+	// path is the path of the value as used in a tree, starting with the
+	// index of the top-level value
+	path          []any
+	before, after any
+}
+
+// diffValues returns the differences between two sequences of JSON values,
+// such as the input and output of a filter. It stops with the error of ctx
+// once ctx is done.
+func diffValues(ctx context.Context, before, after []any) ([]diffEntry, error) {
+	var entries []diffEntry
+	if err := diffArrays(ctx, &entries, nil, before, after); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// diffValue appends the differences between two values at path to entries.
+func diffValue(ctx context.Context, entries *[]diffEntry, path []any, before, after any) error {
+	switch x := before.(type) {
+	case orderedObject:
+		if y, ok := after.(orderedObject); ok {
+			return diffObjects(ctx, entries, path, x, y)
+		}
+	case []any:
+		if y, ok := after.([]any); ok {
+			return diffArrays(ctx, entries, path, x, y)
+		}
+	}
+
+	if compareJSON(before, after) != 0 {
+		*entries = append(*entries, diffEntry{op: diffChanged, path: path, before: before, after: after})
+	}
+
+	return nil
+}
+
+func diffObjects(ctx context.Context, entries *[]diffEntry, path []any, before, after orderedObject) error {
+	members := make(map[string]any, len(after))
+	for _, entry := range after {
+		members[entry.key] = entry.value
+	}
+
+	seen := make(map[string]bool, len(before))
+	for _, entry := range before {
+		seen[entry.key] = true
+		if v, ok := members[entry.key]; ok {
+			if err := diffValue(ctx, entries, appendPath(path, entry.key), entry.value, v); err != nil {
+				return err
+			}
+		} else {
+			*entries = append(*entries, diffEntry{op: diffRemoved, path: appendPath(path, entry.key), before: entry.value})
+		}
+	}
+
+	for _, entry := range after {
+		if !seen[entry.key] {
+			*entries = append(*entries, diffEntry{op: diffAdded, path: appendPath(path, entry.key), after: entry.value})
+		}
+	}
+
+	return nil
+}
+
+// diffArrays appends the differences between two arrays to entries.
+// Elements which are equal in both arrays are matched first, so that
+// removing or inserting an element is not reported as a change to every
+// element after it. The elements between two matches are compared in pairs.
+func diffArrays(ctx context.Context, entries *[]diffEntry, path []any, before, after []any) error {
+	matches, err := matchElements(ctx, before, after)
+	if err != nil {
+		return err
+	}
+
+	matches = append(matches, [2]int{len(before), len(after)})
+
+	i, j := 0, 0
+	for _, m := range matches {
+		for i < m[0] && j < m[1] {
+			if err := diffValue(ctx, entries, appendPath(path, i), before[i], after[j]); err != nil {
+				return err
+			}
+
+			i++
+			j++
+		}
+
+		for ; i < m[0]; i++ {
+			*entries = append(*entries, diffEntry{op: diffRemoved, path: appendPath(path, i), before: before[i]})
+		}
+
+		for ; j < m[1]; j++ {
+			*entries = append(*entries, diffEntry{op: diffAdded, path: appendPath(path, j), after: after[j]})
+		}
+
+		i, j = m[0]+1, m[1]+1
+	}
+
+	return nil
+}
+
+// matchElements returns the indices of the elements of the longest common
+// subsequence of before and after.
+func matchElements(ctx context.Context, before, after []any) ([][2]int, error) {
+	n, m := len(before), len(after)
+	if n == 0 || m == 0 {
+		return nil, nil
+	}
+
+	// Each element is encoded once, rather than for each comparison
+	beforeKeys := make([]string, n)
+	for i, v := range before {
+		beforeKeys[i] = equalityKey(v)
+	}
+
+	afterKeys := make([]string, m)
+	for j, v := range after {
+		afterKeys[j] = equalityKey(v)
+	}
+
+	equal := func(i, j int) bool {
+		return beforeKeys[i] == afterKeys[j]
+	}
+
+	if n*m > maxDiffCells {
+		var matches [][2]int
+		for i := range min(n, m) {
+			if equal(i, i) {
+				matches = append(matches, [2]int{i, i})
+			}
+		}
+
+		return matches, nil
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:]
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for j := m - 1; j >= 0; j-- {
+			if equal(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(i, j):
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return matches, nil
+}
+
+// equalityKey returns a string which is the same for two values exactly when
+// compareJSON reports them as equal.
+func equalityKey(v any) string {
+	var b strings.Builder
+	writeEqualityKey(&b, v)
+	return b.String()
+}
+
+func writeEqualityKey(b *strings.Builder, v any) {
+	switch v := v.(type) {
+	case json.Number:
+		// Numbers are equal when their values are, e.g. 1 and 1.0
+		if x, ok := new(big.Float).SetString(v.String()); ok {
+			b.WriteString("n" + x.Text('g', -1) + ";")
+		} else {
+			b.WriteString("N" + strconv.Quote(v.String()) + ";")
+		}
+	case string:
+		b.WriteString("s" + strconv.Quote(v) + ";")
+	case []any:
+		b.WriteString("[")
+		for _, e := range v {
+			writeEqualityKey(b, e)
+		}
+		b.WriteString("]")
+	case orderedObject:
+		b.WriteString("{")
+		for _, e := range v.sorted() {
+			b.WriteString(strconv.Quote(e.key) + ":")
+			writeEqualityKey(b, e.value)
+		}
+		b.WriteString("}")
+	default:
+		fmt.Fprintf(b, "%d;", jsonRank(v))
+	}
+}
+
+// appendPath returns a copy of path with key added to its end.
+func appendPath(path []any, key any) []any {
+	return append(path[:len(path):len(path)], key)
+}
+
+// diffView shows the differences between the output of the filter and the
+// input, or the output of another filter, in place of the text of the output
+// pane.
+type diffView struct {
+	view    *tview.TextView
+	enabled atomic.Bool
+
+	// The filter whose output the output is compared with. An empty
+	// filter compares the output with the input.
+	base atomic.Pointer[string]
+
+	// A summary of the differences for the title of the pane
+	summary string
+	lines   int
+}
+
+func newDiffView() *diffView {
+	d := &diffView{view: tview.NewTextView()}
+	d.view.SetDynamicColors(true).SetWrap(false).SetBorder(true)
+	return d
+}
+
+// Base returns the filter whose output the output is compared with, or an
+// empty string if it is compared with the input.
+func (d *diffView) Base() string {
+	if base := d.base.Load(); base != nil {
+		return *base
+	}
+
+	return ""
+}
+
+// SetBase sets the filter whose output the output is compared with.
+func (d *diffView) SetBase(filter string) {
+	d.base.Store(&filter)
+}
+
+// SetEntries replaces the differences shown in the view.
+func (d *diffView) SetEntries(entries []diffEntry, topLevel int) {
+	counts := make(map[diffOp]int)
+	for _, e := range entries {
+		counts[e.op]++
+	}
+
+	d.summary = fmt.Sprintf("%d added, %d removed, %d changed", counts[diffAdded], counts[diffRemoved], counts[diffChanged])
+	d.lines = len(entries)
+
+	if len(entries) == 0 {
+		d.view.SetText("[::d]No differences[::D]")
+		d.lines = 1
+		return
+	}
+
+	d.view.SetText(formatDiff(entries, topLevel > 1))
+}
+
+// Status describes what the output is compared with and the differences for
+// the title of the pane.
+func (d *diffView) Status() string {
+	base := "input"
+	if filter := d.Base(); filter != "" {
+		base = strings.Join(strings.Fields(filter), " ")
+	}
+
+	if d.summary == "" {
+		return "diff with " + base
+	}
+
+	return fmt.Sprintf("diff with %s · %s", base, d.summary)
+}
+
+// formatDiff returns the text of the diff view for entries. If several
+// values are compared, each path is preceded by the index of its top-level
+// value.
+func formatDiff(entries []diffEntry, indexed bool) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		path := jqPath(e.path)
+		if indexed {
+			path = fmt.Sprintf("#%d %s", e.path[0], path)
+		}
+
+		path = tview.Escape(path)
+		switch e.op {
+		case diffAdded:
+			fmt.Fprintf(&b, "[green]+ %s: %s[-]", path, tview.Escape(compactJSON(e.after)))
+		case diffRemoved:
+			fmt.Fprintf(&b, "[red]- %s: %s[-]", path, tview.Escape(compactJSON(e.before)))
+		case diffChanged:
+			fmt.Fprintf(&b, "[yellow]~ %s: %s → %s[-]", path, tview.Escape(compactJSON(e.before)), tview.Escape(compactJSON(e.after)))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffValues(t *testing.T) {
+	before := []any{orderedObject{
+		{key: "a", value: json.Number("1")},
+		{key: "b", value: []any{"x", "y", "z"}},
+		{key: "c", value: nil},
+	}}
+	after := []any{orderedObject{
+		{key: "a", value: json.Number("1.0")},
+		{key: "b", value: []any{"y", "z", "w"}},
+		{key: "d", value: orderedObject{}},
+	}}
+
+	diff := func(before, after []any) []diffEntry {
+		entries, err := diffValues(context.Background(), before, after)
+		require.NoError(t, err)
+		return entries
+	}
+
+	// Equal numbers are not changed and removing the first element of an
+	// array does not change the elements after it
+	assert.Equal(t, []diffEntry{
+		{op: diffRemoved, path: []any{0, "b", 0}, before: "x"},
+		{op: diffAdded, path: []any{0, "b", 2}, after: "w"},
+		{op: diffRemoved, path: []any{0, "c"}},
+		{op: diffAdded, path: []any{0, "d"}, after: orderedObject{}},
+	}, diff(before, after))
+
+	assert.Equal(t, []diffEntry{
+		{op: diffChanged, path: []any{0}, before: json.Number("1"), after: "1"},
+		{op: diffAdded, path: []any{1}, after: true},
+	}, diff([]any{json.Number("1")}, []any{"1", true}))
+
+	assert.Empty(t, diff(before, before))
+
+	// Elements are matched as compareJSON compares them
+	assert.Empty(t, diff([]any{[]any{json.Number("1e2"), "a"}}, []any{[]any{json.Number("100"), "a"}}))
+	assert.Len(t, diff([]any{[]any{"1"}}, []any{[]any{json.Number("1")}}), 1)
+
+	// Objects are equal whatever order their members are in
+	a := orderedObject{{key: "a", value: json.Number("1")}, {key: "b", value: json.Number("2")}}
+	b := orderedObject{{key: "b", value: json.Number("2.0")}, {key: "a", value: json.Number("1")}}
+	assert.Empty(t, diff([]any{[]any{a}}, []any{[]any{b}}))
+}
+
+func TestEqualityKey(t *testing.T) {
+	values := []any{
+		nil, false, true, json.Number("1"), json.Number("1.0"), json.Number("2"),
+		"1", "a", []any{}, []any{"a"}, []any{"a", "b"}, []any{[]any{"a"}, "b"},
+		orderedObject{}, orderedObject{{key: "a", value: json.Number("1")}},
+		orderedObject{{key: "a", value: json.Number("1.0")}},
+		orderedObject{{key: "a", value: json.Number("1")}, {key: "b", value: json.Number("2")}},
+		orderedObject{{key: "b", value: json.Number("2")}, {key: "a", value: json.Number("1")}},
+		orderedObject{{key: "b", value: json.Number("1")}, {key: "a", value: json.Number("2")}},
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			assert.Equal(t, compareJSON(a, b) == 0, equalityKey(a) == equalityKey(b), "%#v %#v", a, b)
+		}
+	}
+}
+
+func TestDiffValuesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	before := make([]any, 100)
+	after := make([]any, 100)
+	for i := range before {
+		before[i] = json.Number(strconv.Itoa(i))
+		after[i] = json.Number(strconv.Itoa(i + 1))
+	}
+
+	_, err := diffValues(ctx, before, after)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFormatDiff(t *testing.T) {
+	entries := []diffEntry{
+		{op: diffAdded, path: []any{0, "a b"}, after: []any{json.Number("1")}},
+		{op: diffRemoved, path: []any{0, "c", 2}, before: "[x]"},
+		{op: diffChanged, path: []any{1}, before: nil, after: false},
+	}
+
+	assert.Equal(t, `[green]+ .["a b"[]: [1[][-]
+[red]- .c[2[]: "[x[]"[-]
+[yellow]~ .: null → false[-]`, formatDiff(entries, false))
+
+	assert.Equal(t, "[yellow]~ #1 .: null → false[-]", formatDiff(entries[2:], true))
+}
+
+func TestDiffViewStatus(t *testing.T) {
+	d := newDiffView()
+	assert.Equal(t, "diff with input", d.Status())
+
+	d.SetEntries([]diffEntry{{op: diffAdded, path: []any{0, "a"}}}, 1)
+	assert.Equal(t, "diff with input · 1 added, 0 removed, 0 changed", d.Status())
+
+	d.SetBase(".items\n| map(.name)")
+	assert.Equal(t, "diff with .items | map(.name) · 1 added, 0 removed, 0 changed", d.Status())
+}
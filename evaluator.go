@@ -0,0 +1,198 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// Evaluator runs a jq filter against an input document and writes the results
+// to w.
+type Evaluator interface {
+	Evaluate(ctx context.Context, input string, filter string, opts options.Options, w io.Writer) error
+}
+
+// Exit codes used by jq, which ijq mirrors when exiting.
+const (
+	exitFalsy     = 1
+	exitUsage     = 2
+	exitCompile   = 3
+	exitNoOutput  = 4
+	exitRuntime   = 5
+	exitCancelled = 130
+)
+
+// EvalError is returned by an Evaluator when the filter could not be compiled
+// or failed while running.
+type EvalError struct {
+	Stderr []byte
+
+	// Code is the exit code jq would have exited with
+	Code int
+}
+
+func (e *EvalError) Error() string {
+	if len(e.Stderr) == 0 {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return strings.TrimSpace(string(e.Stderr))
+}
+
+func (e *EvalError) ExitCode() int {
+	return e.Code
+}
+
+// inputFile describes the file the input of an evaluation was read from.
+type inputFile struct {
+	// name is the value of input_filename
+	name string
+}
+
+type inputFileKey struct{}
+
+// withInputFile returns a copy of ctx which tells evaluators that the input
+// was read from file.
+func withInputFile(ctx context.Context, file inputFile) context.Context {
+	return context.WithValue(ctx, inputFileKey{}, file)
+}
+
+// inputFileFrom returns the file the input was read from, if known.
+func inputFileFrom(ctx context.Context) (inputFile, bool) {
+	file, ok := ctx.Value(inputFileKey{}).(inputFile)
+	return file, ok
+}
+
+func newEvaluator(opts options.Options) Evaluator {
+	switch opts.Engine {
+	case "gojq":
+		return &gojqEvaluator{}
+	default:
+		return execEvaluator{}
+	}
+}
+
+// errorOutput returns the diagnostic output of a failed evaluation. The second
+// return value is false if the evaluation was cancelled rather than failing on
+// its own.
+func errorOutput(err error) (string, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() == -1 {
+			return "", false
+		}
+
+		return string(exitErr.Stderr), true
+	}
+
+	var evalErr *EvalError
+	if errors.As(err, &evalErr) {
+		return string(evalErr.Stderr), true
+	}
+
+	return "", false
+}
+
+// exitCode returns the exit code ijq should use after the final evaluation of
+// the filter failed with err.
+func exitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+
+	return exitUsage
+}
+
+// execEvaluator runs filters by spawning the configured jq binary.
+type execEvaluator struct{}
+
+func (execEvaluator) Evaluate(ctx context.Context, input string, filter string, opts options.Options, w io.Writer) error {
+	// jq reads the input from stdin, which is the input ijq loaded and
+	// not the file as it is now, so the name of the file is given to the
+	// filter instead
+	var def string
+	if file, ok := inputFileFrom(ctx); ok {
+		quoted, _ := json.Marshal(file.name)
+		def = fmt.Sprintf("def input_filename: %s; ", quoted)
+		filter = insertDefinition(filter, def)
+	}
+
+	args := append(opts.ToSlice(), filter)
+	if bool(opts.PositionalArgs) || bool(opts.PositionalJSONArgs) {
+		args = append(args, opts.Positional...)
+	}
+
+	cmd := exec.CommandContext(ctx, string(opts.JQCommand), args...)
+
+	var b bytes.Buffer
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = w
+	cmd.Stderr = &b
+
+	if err := cmd.Run(); err != nil {
+		if exiterr, ok := err.(*exec.ExitError); ok {
+			// Errors quote the filter, which should read as it was
+			// written
+			exiterr.Stderr = b.Bytes()
+			if def != "" {
+				exiterr.Stderr = bytes.ReplaceAll(exiterr.Stderr, []byte(def), nil)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// insertDefinition returns filter with def added after its module
+// directives, which must come first. It is on the same line as them so that
+// the line numbers jq reports are unchanged.
+func insertDefinition(filter, def string) string {
+	offset := 0
+	directive := false
+	for _, t := range lex(filter) {
+		if directive {
+			if t.kind == tokenPunct && t.text == ";" {
+				directive = false
+				offset = t.end
+			}
+			continue
+		}
+
+		if t.kind == tokenIdent && (t.text == "module" || t.text == "import" || t.text == "include") {
+			directive = true
+			continue
+		}
+
+		return filter[:offset] + def + filter[offset:]
+	}
+
+	// Definitions cannot end a filter, so nothing is added to a filter
+	// without a body
+	return filter
+}
@@ -66,3 +66,42 @@ func TestLegacyFlagsOverrideConfigValues(t *testing.T) {
 	assert.Equal(t, options.JQCommand("custom-jq"), opts.JQCommand)
 	assert.Equal(t, options.HideInputPane(true), opts.HideInputPane)
 }
+
+func TestNamedArgsFlags(t *testing.T) {
+	opts := options.Options{}
+
+	var out bytes.Buffer
+	flagSet, filterFile, _ := newFlagSet("ijq", &opts, &out)
+	args := joinNamedArgs(flagSet, []string{
+		"-c",
+		"-f", "filter.jq",
+		"--arg", "name", "a=b",
+		"-argjson", "obj", `{"a":1}`,
+		"--slurpfile", "data", "data.json",
+		"--rawfile", "text", "notes.txt",
+		"input.json",
+		"--arg", "ignored", "value",
+	})
+
+	err := flagSet.Parse(args)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "filter.jq", *filterFile)
+	assert.Equal(t, options.StringArgs{{Name: "name", Value: "a=b"}}, opts.StringArgs)
+	assert.Equal(t, options.JSONArgs{{Name: "obj", Value: `{"a":1}`}}, opts.JSONArgs)
+	assert.Equal(t, options.SlurpFiles{{Name: "data", Value: "data.json"}}, opts.SlurpFiles)
+	assert.Equal(t, options.RawFiles{{Name: "text", Value: "notes.txt"}}, opts.RawFiles)
+	assert.Equal(t, []string{"input.json", "--arg", "ignored", "value"}, flagSet.Args())
+}
+
+func TestVariableNames(t *testing.T) {
+	opts := options.Options{
+		StringArgs: options.StringArgs{{Name: "foo", Value: "1"}},
+		JSONArgs:   options.JSONArgs{{Name: "bar", Value: "2"}, {Name: "foo", Value: "3"}},
+	}
+
+	assert.Equal(t, []string{"ENV", "ARGS", "__loc__", "foo", "bar"}, variableNames(opts))
+
+	opts.Engine = "gojq"
+	assert.Equal(t, []string{"ENV", "ARGS", "foo", "bar"}, variableNames(opts))
+}
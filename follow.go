@@ -0,0 +1,134 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// followInterval is how long the follower waits after a value arrives before
+// handing it to the filter, so that values arriving together are handled in a
+// single run.
+const followInterval = 100 * time.Millisecond
+
+// follower collects newline delimited values as they are read from a stream,
+// retaining at most a fixed number of the most recent values.
+type follower struct {
+	mu     sync.Mutex
+	values []string
+	added  int
+	limit  int
+
+	// trimmed is set when values are discarded, until the next call to
+	// Take
+	trimmed bool
+
+	// ready receives a value whenever new values are available
+	ready chan struct{}
+}
+
+func newFollower(limit int) *follower {
+	return &follower{
+		limit: limit,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// Run reads lines from r until it is exhausted. The ready channel is closed
+// when Run returns.
+func (f *follower) Run(r io.Reader) error {
+	defer close(f.ready)
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			f.add(line)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+func (f *follower) add(value string) {
+	f.mu.Lock()
+	f.values = append(f.values, value)
+	f.added++
+	f.trim()
+	f.mu.Unlock()
+
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// trim discards the oldest values above the limit. f.mu must be held.
+func (f *follower) trim() {
+	if n := len(f.values) - f.limit; n > 0 {
+		f.values = append(f.values[:0], f.values[n:]...)
+		f.trimmed = true
+	}
+
+	f.added = min(f.added, len(f.values))
+}
+
+// SetLimit changes the number of retained values.
+func (f *follower) SetLimit(limit int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.limit = limit
+	f.trim()
+}
+
+// Take returns all retained values and the values which were added since the
+// last call to Take, each as newline delimited text, and whether any values
+// were discarded since then, in which case all no longer starts with the
+// values returned before.
+func (f *follower) Take() (all string, added string, trimmed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all = joinLines(f.values)
+	added = joinLines(f.values[len(f.values)-f.added:])
+	trimmed = f.trimmed
+	f.added = 0
+	f.trimmed = false
+
+	return all, added, trimmed
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
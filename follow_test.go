@@ -0,0 +1,82 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowerRun(t *testing.T) {
+	f := newFollower(10)
+	require.NoError(t, f.Run(strings.NewReader("{\"a\":1}\n\n{\"a\":2}\r\n{\"a\":3}")))
+
+	all, added, trimmed := f.Take()
+	assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n{\"a\":3}\n", all)
+	assert.Equal(t, all, added)
+	assert.False(t, trimmed)
+
+	_, ok := <-f.ready
+	assert.True(t, ok)
+	_, ok = <-f.ready
+	assert.False(t, ok)
+}
+
+func TestFollowerTake(t *testing.T) {
+	f := newFollower(10)
+	f.add("1")
+	f.add("2")
+	f.Take()
+
+	f.add("3")
+	all, added, trimmed := f.Take()
+	assert.Equal(t, "1\n2\n3\n", all)
+	assert.Equal(t, "3\n", added)
+	assert.False(t, trimmed)
+
+	_, added, _ = f.Take()
+	assert.Empty(t, added)
+}
+
+func TestFollowerLimit(t *testing.T) {
+	f := newFollower(2)
+	for _, v := range []string{"1", "2", "3"} {
+		f.add(v)
+	}
+
+	all, added, trimmed := f.Take()
+	assert.Equal(t, "2\n3\n", all)
+	assert.Equal(t, "2\n3\n", added)
+	assert.True(t, trimmed)
+
+	f.add("4")
+	f.SetLimit(1)
+	all, added, trimmed = f.Take()
+	assert.Equal(t, "4\n", all)
+	assert.Equal(t, "4\n", added)
+	assert.True(t, trimmed)
+
+	// Values are only discarded once the limit is reached again
+	f.SetLimit(2)
+	f.add("5")
+	_, _, trimmed = f.Take()
+	assert.False(t, trimmed)
+}
@@ -4,20 +4,25 @@ go 1.26
 
 require (
 	codeberg.org/emersion/go-scfg v0.1.0
+	github.com/BurntSushi/toml v1.6.0
 	github.com/gdamore/tcell/v2 v2.7.1
+	github.com/itchyny/gojq v0.12.17
+	github.com/klauspost/compress v1.18.0
+	github.com/mattn/go-runewidth v0.0.15
 	github.com/rivo/tview v0.0.0-20241103174730-c76f7879f592
+	github.com/rivo/uniseg v0.4.7
 	github.com/stretchr/testify v1.7.0
+	github.com/ulikunitz/xz v0.5.9
 	golang.org/x/term v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
+	github.com/itchyny/timefmt-go v0.1.6 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,1019 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/itchyny/gojq"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// errHalt stops processing of any remaining inputs when the filter calls halt
+// or halt_error.
+var errHalt = errors.New("halt")
+
+// gojqEvaluator runs filters in-process using the gojq library.
+type gojqEvaluator struct {
+	mu sync.Mutex
+
+	// parsed holds the inputs parsed most recently, the most recently
+	// used first, so that switching between tabs or running a filter
+	// against each of them does not parse their inputs again
+	parsed []*parsedInput
+
+	// gojq normalizes the numbers in its input in place, which writes to
+	// the parsed values shared between evaluations, so filters are run
+	// on them one at a time. running holds a value while a filter runs,
+	// so that filters waiting for their turn can be cancelled.
+	running chan struct{}
+}
+
+// acquire waits until no other filter is running, or until ctx is done.
+func (e *gojqEvaluator) acquire(ctx context.Context) error {
+	e.mu.Lock()
+	if e.running == nil {
+		e.running = make(chan struct{}, 1)
+	}
+	running := e.running
+	e.mu.Unlock()
+
+	select {
+	case running <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release lets the next filter run.
+func (e *gojqEvaluator) release() {
+	<-e.running
+}
+
+func (e *gojqEvaluator) Evaluate(ctx context.Context, input string, filter string, opts options.Options, w io.Writer) error {
+	query, err := gojq.Parse(filter)
+	if err != nil {
+		return &EvalError{Stderr: []byte(fmt.Sprintf("jq: error: %s\n", err)), Code: exitCompile}
+	}
+
+	// Errors in the input are reported against the file it was read from
+	file, _ := inputFileFrom(ctx)
+	location := cmp.Or(file.name, "<stdin>")
+
+	inputs, err := e.inputs(ctx, input, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+
+		return &EvalError{Stderr: []byte(fmt.Sprintf("jq: error (at %s): %s\n", location, err)), Code: exitUsage}
+	}
+
+	names, vars, err := namedArgs(opts)
+	if err != nil {
+		return &EvalError{Stderr: []byte(fmt.Sprintf("jq: error: %s\n", err)), Code: exitUsage}
+	}
+
+	compilerOptions := []gojq.CompilerOption{
+		gojq.WithInputIter(inputs),
+		gojq.WithEnvironLoader(os.Environ),
+		gojq.WithVariables(names),
+		gojq.WithFunction("input_filename", 0, 0, func(any, []any) any {
+			if file.name == "" {
+				return nil
+			}
+
+			return file.name
+		}),
+	}
+
+	if len(opts.LibraryPaths) > 0 {
+		compilerOptions = append(compilerOptions, gojq.WithModuleLoader(gojq.NewModuleLoader(opts.LibraryPaths)))
+	}
+
+	code, err := gojq.Compile(query, compilerOptions...)
+	if err != nil {
+		return &EvalError{Stderr: []byte(fmt.Sprintf("jq: error: %s\n", err)), Code: exitCompile}
+	}
+
+	enc := newJSONEncoder(opts)
+	bw := bufio.NewWriter(w)
+
+	var (
+		stderr  strings.Builder
+		status  int
+		last    any
+		outputs int
+	)
+
+	run := func(v any) error {
+		iter := code.RunWithContext(ctx, v, vars...)
+		for {
+			out, ok := iter.Next()
+			if !ok {
+				return nil
+			}
+
+			if err, ok := out.(error); ok {
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					return err
+				}
+
+				var haltErr *gojq.HaltError
+				if errors.As(err, &haltErr) {
+					// As in jq, strings are written as they are and
+					// other values as JSON on a line of their own
+					if s, ok := haltErr.Value().(string); ok {
+						stderr.WriteString(s)
+					} else if v := haltErr.Value(); v != nil {
+						w := bufio.NewWriter(&stderr)
+						(&jsonEncoder{}).writeValue(w, v, 0)
+						w.WriteByte('\n')
+						w.Flush()
+					}
+
+					status = haltErr.ExitCode()
+					return errHalt
+				}
+
+				fmt.Fprintf(&stderr, "jq: error (at %s): %s\n", location, err)
+				status = exitRuntime
+				return nil
+			}
+
+			last = out
+			outputs++
+			if err := enc.encode(bw, out); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := e.acquire(ctx); err != nil {
+		return err
+	}
+
+	if opts.NullInput {
+		err = run(nil)
+	} else {
+		for {
+			v, ok := inputs.Next()
+			if !ok {
+				break
+			}
+
+			if parseErr, ok := v.(error); ok {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+					break
+				}
+
+				fmt.Fprintf(&stderr, "jq: error (at %s): %s\n", location, parseErr)
+				status = exitUsage
+				break
+			}
+
+			if err = run(v); err != nil {
+				break
+			}
+		}
+	}
+	e.release()
+
+	halted := errors.Is(err, errHalt)
+	if halted {
+		err = nil
+	}
+
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if status == 0 && !halted && bool(opts.ExitStatus) {
+		// Mirror the exit status jq uses for --exit-status
+		if outputs == 0 {
+			status = exitNoOutput
+		} else if last == nil || last == false {
+			status = exitFalsy
+		}
+	}
+
+	if stderr.Len() > 0 || status != 0 {
+		return &EvalError{Stderr: []byte(stderr.String()), Code: status}
+	}
+
+	return nil
+}
+
+// namedArgs returns the names and values of the variables given with --arg and
+// friends, including $ARGS.
+func namedArgs(opts options.Options) ([]string, []any, error) {
+	var (
+		names  []string
+		values []any
+		named  = make(map[string]any)
+	)
+
+	add := func(name string, value any) {
+		names = append(names, "$"+name)
+		values = append(values, value)
+		named[name] = value
+	}
+
+	for _, arg := range opts.StringArgs {
+		add(arg.Name, arg.Value)
+	}
+
+	for _, arg := range opts.JSONArgs {
+		v, err := parseJSONValue(arg.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON text passed to --argjson: %w", err)
+		}
+
+		add(arg.Name, v)
+	}
+
+	for _, arg := range opts.SlurpFiles {
+		contents, err := os.ReadFile(arg.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s: %w", arg.Value, err)
+		}
+
+		vs, err := parseJSONInputs(string(contents))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON in %s: %w", arg.Value, err)
+		}
+
+		if vs == nil {
+			vs = []any{}
+		}
+
+		add(arg.Name, vs)
+	}
+
+	for _, arg := range opts.RawFiles {
+		contents, err := os.ReadFile(arg.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s: %w", arg.Value, err)
+		}
+
+		add(arg.Name, string(contents))
+	}
+
+	positional := []any{}
+	if opts.PositionalJSONArgs {
+		for _, arg := range opts.Positional {
+			v, err := parseJSONValue(arg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid JSON text passed to --jsonargs: %w", err)
+			}
+
+			positional = append(positional, v)
+		}
+	} else if opts.PositionalArgs {
+		for _, arg := range opts.Positional {
+			positional = append(positional, arg)
+		}
+	}
+
+	names = append(names, "$ARGS")
+	values = append(values, map[string]any{"positional": positional, "named": named})
+
+	return names, values, nil
+}
+
+// valueIter implements gojq.Iter over already parsed input values. The same
+// iterator is shared between the main input loop and the input and inputs
+// builtins, just like jq.
+type valueIter struct {
+	values []any
+	pos    int
+}
+
+func (it *valueIter) Next() (any, bool) {
+	if it.pos >= len(it.values) {
+		return nil, false
+	}
+
+	v := it.values[it.pos]
+	it.pos++
+	return v, true
+}
+
+// maxParsedInputs is the number of parsed inputs a gojqEvaluator keeps.
+const maxParsedInputs = 8
+
+// parsedInput holds the values decoded from a document's input. Decoding runs
+// in its own goroutine so that it is not interrupted when the evaluation which
+// started it is cancelled; the next evaluation picks up the values decoded so
+// far instead of parsing the input again. Evaluations read the values as
+// they are decoded, so that the outputs for the values before malformed
+// input are written.
+type parsedInput struct {
+	input string
+	seq   bool
+
+	mu     sync.Mutex
+	values []any
+	err    error
+	done   bool
+
+	// more is closed when more values are decoded or decoding is done,
+	// if an iterator is waiting for them
+	more chan struct{}
+}
+
+// decode decodes the input, one value at a time.
+func (p *parsedInput) decode() {
+	dec := newJSONDecoder(p.input, p.seq)
+	for {
+		var v any
+		err := dec.Decode(&v)
+
+		p.mu.Lock()
+		if err != nil {
+			if err != io.EOF {
+				p.err = err
+			}
+
+			p.done = true
+		} else {
+			p.values = append(p.values, normalizeNumbers(v))
+		}
+
+		if p.more != nil {
+			close(p.more)
+			p.more = nil
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wait returns the values decoded so far and whether decoding is done. If
+// there are no more than n values and decoding is not done yet, it waits for
+// more until ctx is done.
+func (p *parsedInput) wait(ctx context.Context, n int) ([]any, bool, error) {
+	p.mu.Lock()
+	if len(p.values) > n || p.done {
+		defer p.mu.Unlock()
+		return p.values, p.done, nil
+	}
+
+	if p.more == nil {
+		p.more = make(chan struct{})
+	}
+	more := p.more
+	p.mu.Unlock()
+
+	select {
+	case <-more:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.values, p.done, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// parsedIter implements gojq.Iter over the values of a parsedInput, waiting
+// for each to be decoded. A parse error is produced in place of the
+// malformed value, after which there are no more values.
+type parsedIter struct {
+	ctx context.Context
+	p   *parsedInput
+	pos int
+	end bool
+}
+
+func (it *parsedIter) Next() (any, bool) {
+	if it.end {
+		return nil, false
+	}
+
+	for {
+		values, done, err := it.p.wait(it.ctx, it.pos)
+		if err != nil {
+			it.end = true
+			return err, true
+		}
+
+		if it.pos < len(values) {
+			v := values[it.pos]
+			it.pos++
+			return v, true
+		}
+
+		if done {
+			it.end = true
+			if it.p.err != nil {
+				return it.p.err, true
+			}
+
+			return nil, false
+		}
+	}
+}
+
+// inputs returns the input values for a single evaluation. JSON input is only
+// parsed once and reused by every subsequent evaluation of the same input.
+func (e *gojqEvaluator) inputs(ctx context.Context, input string, opts options.Options) (gojq.Iter, error) {
+	if opts.RawInput {
+		if opts.Slurp {
+			return &valueIter{values: []any{input}}, nil
+		}
+
+		return &valueIter{values: parseRawInputs(input)}, nil
+	}
+
+	if bool(opts.Stream) || bool(opts.StreamErrors) {
+		// Stream events are generated lazily from the input text so that
+		// the whole document never needs to be held in memory as a tree
+		return slurp(newStreamIter(input, opts), opts)
+	}
+
+	seq := bool(opts.Seq)
+
+	e.mu.Lock()
+	i := slices.IndexFunc(e.parsed, func(p *parsedInput) bool {
+		return p.input == input && p.seq == seq
+	})
+
+	var p *parsedInput
+	if i >= 0 {
+		p = e.parsed[i]
+		e.parsed = slices.Delete(e.parsed, i, i+1)
+	} else {
+		p = &parsedInput{input: input, seq: seq}
+		go p.decode()
+		if len(e.parsed) == maxParsedInputs {
+			e.parsed = e.parsed[:len(e.parsed)-1]
+		}
+	}
+	e.parsed = slices.Insert(e.parsed, 0, p)
+	e.mu.Unlock()
+
+	return slurp(&parsedIter{ctx: ctx, p: p}, opts)
+}
+
+// slurp returns an iterator over the single array of the values of inputs if
+// opts.Slurp is set, or inputs itself otherwise. It fails if any value is an
+// error.
+func slurp(inputs gojq.Iter, opts options.Options) (gojq.Iter, error) {
+	if !opts.Slurp {
+		return inputs, nil
+	}
+
+	values := []any{}
+	for {
+		v, ok := inputs.Next()
+		if !ok {
+			break
+		}
+
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+
+	return &valueIter{values: []any{values}}, nil
+}
+
+func parseRawInputs(input string) []any {
+	lines := strings.SplitAfter(input, "\n")
+	values := make([]any, 0, len(lines))
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		values = append(values, strings.TrimSuffix(line, "\n"))
+	}
+
+	return values
+}
+
+func parseJSONInputs(input string) ([]any, error) {
+	return decodeJSONInputs(newJSONDecoder(input, false))
+}
+
+func decodeJSONInputs(dec *json.Decoder) ([]any, error) {
+	var values []any
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return nil, err
+		}
+
+		values = append(values, normalizeNumbers(v))
+	}
+
+	return values, nil
+}
+
+func newJSONDecoder(input string, seq bool) *json.Decoder {
+	var r io.Reader = strings.NewReader(input)
+	if seq {
+		r = rsReader{r}
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec
+}
+
+// rsReader replaces the ASCII record separators which delimit values in
+// application/json-seq (--seq) input with whitespace.
+type rsReader struct {
+	r io.Reader
+}
+
+func (r rsReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	for i := range p[:n] {
+		if p[i] == 0x1e {
+			p[i] = ' '
+		}
+	}
+
+	return n, err
+}
+
+// streamIter converts JSON text into the [path, leaf] events produced by
+// jq's --stream option, reading the input one token at a time.
+type streamIter struct {
+	dec    *json.Decoder
+	stack  []streamFrame
+	errors bool
+	done   bool
+}
+
+type streamFrame struct {
+	object    bool
+	key       any
+	expectKey bool
+}
+
+func newStreamIter(input string, opts options.Options) *streamIter {
+	return &streamIter{
+		dec:    newJSONDecoder(input, bool(opts.Seq)),
+		errors: bool(opts.StreamErrors),
+	}
+}
+
+func (it *streamIter) path() []any {
+	path := make([]any, len(it.stack))
+	for i, frame := range it.stack {
+		path[i] = frame.key
+	}
+
+	return path
+}
+
+// advance moves the innermost container past the value which was just
+// completed.
+func (it *streamIter) advance() {
+	if len(it.stack) == 0 {
+		return
+	}
+
+	frame := &it.stack[len(it.stack)-1]
+	if frame.object {
+		frame.expectKey = true
+	} else {
+		frame.key = frame.key.(int) + 1
+	}
+}
+
+func (it *streamIter) Next() (any, bool) {
+	for !it.done {
+		tok, err := it.dec.Token()
+		if err == io.EOF {
+			if len(it.stack) == 0 {
+				it.done = true
+				break
+			}
+
+			err = io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			it.done = true
+			if it.errors {
+				return []any{err.Error(), it.path()}, true
+			}
+
+			return err, true
+		}
+
+		if n := len(it.stack); n > 0 && it.stack[n-1].expectKey {
+			if key, ok := tok.(string); ok {
+				it.stack[n-1].key = key
+				it.stack[n-1].expectKey = false
+				continue
+			}
+		}
+
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			event := []any{it.path(), normalizeNumbers(tok)}
+			it.advance()
+			return event, true
+		}
+
+		switch delim {
+		case '[', '{':
+			if !it.dec.More() {
+				// Empty containers are leaves
+				it.dec.Token()
+
+				var empty any = []any{}
+				if delim == '{' {
+					empty = map[string]any{}
+				}
+
+				event := []any{it.path(), empty}
+				it.advance()
+				return event, true
+			}
+
+			it.stack = append(it.stack, streamFrame{object: delim == '{', key: 0, expectKey: delim == '{'})
+		case ']', '}':
+			// The closing event holds the path of the last element of
+			// the container
+			path := it.path()
+			if delim == ']' {
+				path[len(path)-1] = path[len(path)-1].(int) - 1
+			}
+
+			it.stack = it.stack[:len(it.stack)-1]
+			it.advance()
+			return []any{path}, true
+		}
+	}
+
+	return nil, false
+}
+
+func parseJSONValue(text string) (any, error) {
+	values, err := parseJSONInputs(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected exactly one value, found %d", len(values))
+	}
+
+	return values[0], nil
+}
+
+// normalizeNumbers converts the json.Number values produced by the decoder
+// into the numeric types understood by gojq.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && math.MinInt <= i && i <= math.MaxInt {
+			return int(i)
+		}
+
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if bi, ok := new(big.Int).SetString(v.String(), 10); ok {
+				return bi
+			}
+		}
+
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i, x := range v {
+			v[i] = normalizeNumbers(x)
+		}
+
+		return v
+	case map[string]any:
+		for k, x := range v {
+			v[k] = normalizeNumbers(x)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// Default colors used by jq. These can be overridden using the JQ_COLORS
+// environment variable.
+var defaultJQColors = []string{"1;30", "0;39", "0;39", "0;39", "0;32", "1;39", "1;39", "34;1"}
+
+type jsonColors struct {
+	null, falseValue, trueValue, number, str, array, object, objectKey string
+}
+
+func parseJQColors(spec string) jsonColors {
+	colors := slices.Clone(defaultJQColors)
+	for i, c := range strings.Split(spec, ":") {
+		if i >= len(colors) {
+			break
+		}
+
+		if c != "" {
+			colors[i] = c
+		}
+	}
+
+	seq := func(c string) string {
+		return "\x1b[" + c + "m"
+	}
+
+	return jsonColors{
+		null:       seq(colors[0]),
+		falseValue: seq(colors[1]),
+		trueValue:  seq(colors[2]),
+		number:     seq(colors[3]),
+		str:        seq(colors[4]),
+		array:      seq(colors[5]),
+		object:     seq(colors[6]),
+		objectKey:  seq(colors[7]),
+	}
+}
+
+// jsonEncoder writes values the same way jq would given the output options.
+type jsonEncoder struct {
+	indent string
+	color  bool
+	colors jsonColors
+	ascii  bool
+	raw    bool
+	join   bool
+	seq    bool
+}
+
+func newJSONEncoder(opts options.Options) *jsonEncoder {
+	enc := &jsonEncoder{
+		indent: "  ",
+		color:  bool(opts.ForceColor) && !bool(opts.Monochrome),
+		ascii:  bool(opts.ASCIIOutput),
+		raw:    bool(opts.RawOutput) || bool(opts.JoinOutput),
+		join:   bool(opts.JoinOutput),
+		seq:    bool(opts.Seq),
+	}
+
+	// As in jq, an indentation of 0 writes each value on a single line
+	enc.indent = strings.Repeat(" ", options.EffectiveInt(&opts.Indent))
+
+	if opts.Tab {
+		enc.indent = "\t"
+	}
+
+	if opts.CompactOutput {
+		enc.indent = ""
+	}
+
+	if enc.color {
+		enc.colors = parseJQColors(os.Getenv("JQ_COLORS"))
+	}
+
+	return enc
+}
+
+func (e *jsonEncoder) encode(w *bufio.Writer, v any) error {
+	if e.seq {
+		w.WriteByte(0x1e)
+	}
+
+	if s, ok := v.(string); ok && e.raw {
+		if e.ascii {
+			e.writeString(w, s, false)
+		} else {
+			w.WriteString(s)
+		}
+	} else {
+		e.writeValue(w, v, 0)
+	}
+
+	if !e.join {
+		w.WriteByte('\n')
+	}
+
+	if w.Buffered() > 8*1024 {
+		return w.Flush()
+	}
+
+	return nil
+}
+
+func (e *jsonEncoder) setColor(w *bufio.Writer, color string) {
+	if e.color {
+		w.WriteString(color)
+	}
+}
+
+func (e *jsonEncoder) resetColor(w *bufio.Writer) {
+	if e.color {
+		w.WriteString("\x1b[0m")
+	}
+}
+
+func (e *jsonEncoder) newline(w *bufio.Writer, depth int) {
+	if e.indent == "" {
+		return
+	}
+
+	w.WriteByte('\n')
+	for range depth {
+		w.WriteString(e.indent)
+	}
+}
+
+func (e *jsonEncoder) writeValue(w *bufio.Writer, v any, depth int) {
+	switch v := v.(type) {
+	case nil:
+		e.setColor(w, e.colors.null)
+		w.WriteString("null")
+		e.resetColor(w)
+	case bool:
+		if v {
+			e.setColor(w, e.colors.trueValue)
+			w.WriteString("true")
+		} else {
+			e.setColor(w, e.colors.falseValue)
+			w.WriteString("false")
+		}
+		e.resetColor(w)
+	case int:
+		e.setColor(w, e.colors.number)
+		w.WriteString(strconv.Itoa(v))
+		e.resetColor(w)
+	case *big.Int:
+		e.setColor(w, e.colors.number)
+		w.WriteString(v.String())
+		e.resetColor(w)
+	case float64:
+		e.setColor(w, e.colors.number)
+		w.WriteString(formatFloat(v))
+		e.resetColor(w)
+	case string:
+		e.setColor(w, e.colors.str)
+		e.writeString(w, v, true)
+		e.resetColor(w)
+	case []any:
+		e.setColor(w, e.colors.array)
+		w.WriteByte('[')
+		for i, x := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			e.newline(w, depth+1)
+			e.resetColor(w)
+			e.writeValue(w, x, depth+1)
+			e.setColor(w, e.colors.array)
+		}
+
+		if len(v) > 0 {
+			e.newline(w, depth)
+		}
+		w.WriteByte(']')
+		e.resetColor(w)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		e.setColor(w, e.colors.object)
+		w.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+
+			e.newline(w, depth+1)
+			e.resetColor(w)
+			e.setColor(w, e.colors.objectKey)
+			e.writeString(w, k, true)
+			e.resetColor(w)
+			e.setColor(w, e.colors.object)
+			w.WriteByte(':')
+			if e.indent != "" {
+				w.WriteByte(' ')
+			}
+			e.resetColor(w)
+			e.writeValue(w, v[k], depth+1)
+			e.setColor(w, e.colors.object)
+		}
+
+		if len(v) > 0 {
+			e.newline(w, depth)
+		}
+		w.WriteByte('}')
+		e.resetColor(w)
+	default:
+		fmt.Fprintf(w, "%v", v)
+	}
+}
+
+func (e *jsonEncoder) writeString(w *bufio.Writer, s string, quote bool) {
+	const hex = "0123456789abcdef"
+
+	if quote {
+		w.WriteByte('"')
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"' && quote:
+			w.WriteString(`\"`)
+		case r == '\\' && quote:
+			w.WriteString(`\\`)
+		case r == '\n' && quote:
+			w.WriteString(`\n`)
+		case r == '\r' && quote:
+			w.WriteString(`\r`)
+		case r == '\t' && quote:
+			w.WriteString(`\t`)
+		case r == '\b' && quote:
+			w.WriteString(`\b`)
+		case r == '\f' && quote:
+			w.WriteString(`\f`)
+		case r < 0x20 && quote, r == 0x7f && quote:
+			w.WriteString(`\u00`)
+			w.WriteByte(hex[r>>4])
+			w.WriteByte(hex[r&0xf])
+		case r >= utf8.RuneSelf && e.ascii:
+			if r > 0xffff {
+				r -= 0x10000
+				fmt.Fprintf(w, `\u%04x\u%04x`, 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+			} else {
+				fmt.Fprintf(w, `\u%04x`, r)
+			}
+		default:
+			w.WriteRune(r)
+		}
+	}
+
+	if quote {
+		w.WriteByte('"')
+	}
+}
+
+func formatFloat(f float64) string {
+	if math.IsNaN(f) {
+		return "null"
+	}
+
+	f = min(max(f, -math.MaxFloat64), math.MaxFloat64)
+
+	format := byte('f')
+	if x := math.Abs(f); x != 0 && (x < 1e-6 || x >= 1e17) {
+		format = 'e'
+	}
+
+	return strconv.FormatFloat(f, format, -1, 64)
+}
@@ -0,0 +1,337 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func evaluateGojq(t *testing.T, input string, filter string, opts options.Options) (string, error) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := (&gojqEvaluator{}).Evaluate(context.Background(), input, filter, opts, &buf)
+	return buf.String(), err
+}
+
+func TestGojqEvaluatorPrettyPrints(t *testing.T) {
+	out, err := evaluateGojq(t, `{"b":[1,2.5,"x"],"a":null}`, ".", options.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": null,\n  \"b\": [\n    1,\n    2.5,\n    \"x\"\n  ]\n}\n", out)
+}
+
+func TestGojqEvaluatorOutputOptions(t *testing.T) {
+	out, err := evaluateGojq(t, `{"a":"x"} {"a":"y"}`, ".a", options.Options{RawOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "x\ny\n", out)
+
+	out, err = evaluateGojq(t, `{"a":"x"} {"a":"y"}`, ".a", options.Options{JoinOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "xy", out)
+
+	out, err = evaluateGojq(t, `{"a":[1,{}]}`, ".", options.Options{CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":[1,{}]}\n", out)
+
+	out, err = evaluateGojq(t, `{"a":[1]}`, ".", options.Options{Indent: options.Indent{Value: 4, IsSet: true}})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n    \"a\": [\n        1\n    ]\n}\n", out)
+
+	out, err = evaluateGojq(t, `{"a":[1]}`, ".", options.Options{Indent: options.Indent{Value: 4, IsSet: true}, Tab: true})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n\t\"a\": [\n\t\t1\n\t]\n}\n", out)
+
+	// An indentation of 0 writes each value on a single line
+	opts := options.Options{}
+	require.NoError(t, opts.Indent.Set("0"))
+	out, err = evaluateGojq(t, `{"a":[1]} [2]`, ".", opts)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":[1]}\n[2]\n", out)
+
+	out, err = evaluateGojq(t, `"é😀"`, ".", options.Options{ASCIIOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "\"\\u00e9\\ud83d\\ude00\"\n", out)
+}
+
+func TestGojqEvaluatorInputOptions(t *testing.T) {
+	out, err := evaluateGojq(t, "1 2 3", "add", options.Options{Slurp: true})
+	require.NoError(t, err)
+	assert.Equal(t, "6\n", out)
+
+	out, err = evaluateGojq(t, "foo\nbar\n", "length", options.Options{RawInput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "3\n3\n", out)
+
+	out, err = evaluateGojq(t, "1 2 3", "[inputs]", options.Options{NullInput: true, CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2,3]\n", out)
+
+	out, err = evaluateGojq(t, "1 2 3 4", "[., input]", options.Options{CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2]\n[3,4]\n", out)
+}
+
+func TestGojqEvaluatorStream(t *testing.T) {
+	input := `{"a":[1,{"b":[]}],"c":{}} 3`
+
+	out, err := evaluateGojq(t, input, ".", options.Options{Stream: true, CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, `[["a",0],1]
+[["a",1,"b"],[]]
+[["a",1,"b"]]
+[["a",1]]
+[["c"],{}]
+[["c"]]
+[[],3]
+`, out)
+
+	out, err = evaluateGojq(t, input, "length", options.Options{Stream: true, Slurp: true})
+	require.NoError(t, err)
+	assert.Equal(t, "7\n", out)
+
+	out, err = evaluateGojq(t, `{"a":[1,{"b":2}]}`, "fromstream(inputs)", options.Options{Stream: true, NullInput: true, CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":[1,{\"b\":2}]}\n", out)
+
+	out, err = evaluateGojq(t, `{"a":[1,`, ".", options.Options{StreamErrors: true, CompactOutput: true})
+	require.NoError(t, err)
+	assert.Contains(t, out, `[["a",0],1]`)
+	assert.Contains(t, out, `["a",1]]`)
+
+	out, err = evaluateGojq(t, `[1,`, ".", options.Options{Stream: true, CompactOutput: true})
+	assert.Equal(t, "[[0],1]\n", out)
+	require.Error(t, err)
+}
+
+func TestGojqEvaluatorSeq(t *testing.T) {
+	out, err := evaluateGojq(t, "\x1e1\x1e[2]", ".", options.Options{Seq: true, CompactOutput: true})
+	require.NoError(t, err)
+	assert.Equal(t, "\x1e1\n\x1e[2]\n", out)
+}
+
+func TestGojqEvaluatorColors(t *testing.T) {
+	t.Setenv("JQ_COLORS", "")
+
+	out, err := evaluateGojq(t, `true`, ".", options.Options{ForceColor: true})
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[0;39mtrue\x1b[0m\n", out)
+}
+
+func TestGojqEvaluatorErrors(t *testing.T) {
+	_, err := evaluateGojq(t, `{}`, ".[", options.Options{})
+	require.Error(t, err)
+	stderr, ok := errorOutput(err)
+	assert.True(t, ok)
+	assert.Contains(t, stderr, "jq: error")
+
+	out, err := evaluateGojq(t, `1 "a" 2`, ". + 1", options.Options{})
+	require.Error(t, err)
+	assert.Equal(t, "2\n3\n", out)
+
+	_, err = evaluateGojq(t, `{`, ".", options.Options{})
+	require.Error(t, err)
+
+	// The values before malformed input are still filtered
+	out, err = evaluateGojq(t, `1 2 {`, ". + 1", options.Options{})
+	assert.Equal(t, "2\n3\n", out)
+	assert.Equal(t, exitUsage, exitCode(err))
+	stderr, _ = errorOutput(err)
+	assert.Contains(t, stderr, "jq: error (at <stdin>)")
+
+	out, err = evaluateGojq(t, `1 2 {`, ".", options.Options{Slurp: true})
+	assert.Empty(t, out)
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestGojqEvaluatorInputFilename(t *testing.T) {
+	out, err := evaluateGojq(t, `1`, "input_filename", options.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "null\n", out)
+
+	var buf bytes.Buffer
+	ctx := withInputFile(context.Background(), inputFile{name: "a.json"})
+	require.NoError(t, (&gojqEvaluator{}).Evaluate(ctx, `1`, "input_filename", options.Options{}, &buf))
+	assert.Equal(t, "\"a.json\"\n", buf.String())
+
+	buf.Reset()
+	err = (&gojqEvaluator{}).Evaluate(ctx, `1 "a"`, ". + 1", options.Options{}, &buf)
+	stderr, ok := errorOutput(err)
+	assert.True(t, ok)
+	assert.Contains(t, stderr, "(at a.json)")
+}
+
+func TestGojqEvaluatorExitCodes(t *testing.T) {
+	tests := []struct {
+		input  string
+		filter string
+		opts   options.Options
+		code   int
+	}{
+		{`1`, ".", options.Options{ExitStatus: true}, 0},
+		{`true null`, ".", options.Options{ExitStatus: true}, exitFalsy},
+		{`false`, ".", options.Options{ExitStatus: true}, exitFalsy},
+		{`1`, "empty", options.Options{ExitStatus: true}, exitNoOutput},
+		{`1`, "empty", options.Options{}, 0},
+		{`1`, ".[", options.Options{}, exitCompile},
+		{`1`, ".a", options.Options{ExitStatus: true}, exitRuntime},
+		{`{`, ".", options.Options{}, exitUsage},
+		{`1`, "halt", options.Options{ExitStatus: true}, 0},
+		{`1`, `"x" | halt_error(3)`, options.Options{}, 3},
+	}
+
+	for _, tt := range tests {
+		_, err := evaluateGojq(t, tt.input, tt.filter, tt.opts)
+		if tt.code == 0 {
+			assert.NoError(t, err, tt.filter)
+		} else {
+			assert.Equal(t, tt.code, exitCode(err), tt.filter)
+		}
+	}
+}
+
+func TestGojqEvaluatorHaltError(t *testing.T) {
+	for filter, want := range map[string]string{
+		`"x" | halt_error`:        "x",
+		`"x\n" | halt_error`:      "x\n",
+		`{"a": [1]} | halt_error`: "{\"a\":[1]}\n",
+		`halt`:                    "",
+	} {
+		_, err := evaluateGojq(t, `1`, filter, options.Options{})
+		stderr, _ := errorOutput(err)
+		assert.Equal(t, want, stderr, filter)
+	}
+}
+
+func TestGojqEvaluatorCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	err := (&gojqEvaluator{}).Evaluate(ctx, "null", "range(1e9)", options.Options{}, &buf)
+	require.Error(t, err)
+
+	_, ok := errorOutput(err)
+	assert.False(t, ok)
+}
+
+func TestGojqEvaluatorParsesInputOnce(t *testing.T) {
+	e := &gojqEvaluator{}
+	input := `{"a":1} {"a":2}`
+
+	var buf bytes.Buffer
+	require.NoError(t, e.Evaluate(context.Background(), input, ".a", options.Options{}, &buf))
+	require.Len(t, e.parsed, 1)
+	parsed := e.parsed[0]
+
+	buf.Reset()
+	require.NoError(t, e.Evaluate(context.Background(), input, "add", options.Options{Slurp: true, CompactOutput: true}, &buf))
+	assert.Equal(t, []*parsedInput{parsed}, e.parsed)
+	assert.Equal(t, "{\"a\":2}\n", buf.String())
+
+	// Other inputs are parsed separately, and the first is kept
+	buf.Reset()
+	require.NoError(t, e.Evaluate(context.Background(), "3", ".", options.Options{}, &buf))
+	require.Len(t, e.parsed, 2)
+	assert.Same(t, parsed, e.parsed[1])
+	assert.Equal(t, "3\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, e.Evaluate(context.Background(), input, ".a", options.Options{}, &buf))
+	assert.Same(t, parsed, e.parsed[0])
+	assert.Equal(t, "1\n2\n", buf.String())
+
+	// The least recently used input is dropped
+	for i := range maxParsedInputs {
+		require.NoError(t, e.Evaluate(context.Background(), strconv.Itoa(i), ".", options.Options{}, &buf))
+	}
+	assert.Len(t, e.parsed, maxParsedInputs)
+	assert.NotContains(t, e.parsed, parsed)
+}
+
+func TestGojqEvaluatorConcurrent(t *testing.T) {
+	e := &gojqEvaluator{}
+	input := `{"a":{"b":[1,2,3]}}`
+
+	// Filters run at the same time share the parsed input
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			var buf bytes.Buffer
+			assert.NoError(t, e.Evaluate(context.Background(), input, ".a.b | add", options.Options{}, &buf))
+			assert.Equal(t, "6\n", buf.String())
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestGojqEvaluatorWaitCancelled(t *testing.T) {
+	e := &gojqEvaluator{}
+	require.NoError(t, e.acquire(context.Background()))
+	defer e.release()
+
+	// A filter waiting for another one to finish stops when it is
+	// cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var buf bytes.Buffer
+	err := e.Evaluate(ctx, "1", ".", options.Options{}, &buf)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, buf.String())
+}
+
+func TestGojqEvaluatorNamedArgs(t *testing.T) {
+	dir := t.TempDir()
+	slurpFile := filepath.Join(dir, "data.json")
+	rawFile := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(slurpFile, []byte("1 2"), 0o644))
+	require.NoError(t, os.WriteFile(rawFile, []byte("hello\n"), 0o644))
+
+	opts := options.Options{
+		NullInput:          true,
+		CompactOutput:      true,
+		StringArgs:         options.StringArgs{{Name: "s", Value: "str"}},
+		JSONArgs:           options.JSONArgs{{Name: "j", Value: `{"a":1}`}},
+		SlurpFiles:         options.SlurpFiles{{Name: "f", Value: slurpFile}},
+		RawFiles:           options.RawFiles{{Name: "r", Value: rawFile}},
+		PositionalJSONArgs: true,
+		Positional:         options.Positional{"1", `"x"`},
+	}
+
+	out, err := evaluateGojq(t, "", "[$s, $j, $f, $r, $ARGS.positional, ($ARGS.named | keys)]", opts)
+	require.NoError(t, err)
+	assert.Equal(t, `["str",{"a":1},[1,2],"hello\n",[1,"x"],["f","j","r","s"]]`+"\n", out)
+
+	opts.JSONArgs = options.JSONArgs{{Name: "j", Value: "{"}}
+	_, err = evaluateGojq(t, "", "$j", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--argjson")
+}
@@ -15,23 +15,58 @@ type Option struct {
 	Flag        string
 	Type        string
 	Description string
+	Values      []string
+
+	// JQFlag is the flag passed to jq for this option. Options without a
+	// JQFlag only affect ijq itself.
+	JQFlag string
+
+	// Min, Max and Default are the bounds and default value of int options.
+	// An int option which is not set uses the default.
+	Min, Max, Default int
+
+	// Step is the amount an int option is adjusted by in the Configure
+	// subview. It defaults to 1.
+	Step int
 }
 
 var data = []Option{
-	{Name: "CompactOutput", Flag: "c", Type: "bool", Description: "Compact output"},
-	{Name: "NullInput", Flag: "n", Type: "bool", Description: "Use null input"},
-	{Name: "Slurp", Flag: "s", Type: "bool", Description: "Slurp input"},
-	{Name: "RawOutput", Flag: "r", Type: "bool", Description: "Raw output"},
-	{Name: "JoinOutput", Flag: "j", Type: "bool", Description: "Join output"},
-	{Name: "ASCIIOutput", Flag: "a", Type: "bool", Description: "ASCII output"},
-	{Name: "RawInput", Flag: "R", Type: "bool", Description: "Read raw strings"},
-	{Name: "Monochrome", Flag: "M", Type: "bool", Description: "Monochrome output"},
-	{Name: "ForceColor", Flag: "C", Type: "bool", Description: "Force color"},
-	{Name: "SortKeys", Flag: "S", Type: "bool", Description: "Sort keys"},
-	{Name: "LibraryPaths", Flag: "L", Type: "[]string", Description: "Add path to library search path"},
+	{Name: "CompactOutput", Flag: "c", JQFlag: "-c", Type: "bool", Description: "Compact output"},
+	{Name: "NullInput", Flag: "n", JQFlag: "-n", Type: "bool", Description: "Use null input"},
+	{Name: "Slurp", Flag: "s", JQFlag: "-s", Type: "bool", Description: "Slurp input"},
+	{Name: "RawOutput", Flag: "r", JQFlag: "-r", Type: "bool", Description: "Raw output"},
+	{Name: "JoinOutput", Flag: "j", JQFlag: "-j", Type: "bool", Description: "Join output"},
+	{Name: "ASCIIOutput", Flag: "a", JQFlag: "-a", Type: "bool", Description: "ASCII output"},
+	{Name: "RawInput", Flag: "R", JQFlag: "-R", Type: "bool", Description: "Read raw strings"},
+	{Name: "Monochrome", Flag: "M", JQFlag: "-M", Type: "bool", Description: "Monochrome output"},
+	{Name: "ForceColor", Flag: "C", JQFlag: "-C", Type: "bool", Description: "Force color"},
+	{Name: "SortKeys", Flag: "S", JQFlag: "-S", Type: "bool", Description: "Sort keys"},
+	{Name: "Indent", Flag: "indent", JQFlag: "--indent", Type: "int", Description: "Indentation", Min: 0, Max: 7, Default: 2},
+	{Name: "Tab", Flag: "tab", JQFlag: "--tab", Type: "bool", Description: "Indent with tabs"},
+	{Name: "Stream", Flag: "stream", JQFlag: "--stream", Type: "bool", Description: "Parse input in streaming fashion"},
+	{Name: "StreamErrors", Flag: "stream-errors", JQFlag: "--stream-errors", Type: "bool", Description: "Stream input and report parse errors as values"},
+	{Name: "Seq", Flag: "seq", JQFlag: "--seq", Type: "bool", Description: "Use application/json-seq records"},
+	{Name: "ExitStatus", Flag: "e", JQFlag: "-e", Type: "bool", Description: "Set exit status from the last output"},
+	{Name: "LibraryPaths", Flag: "L", JQFlag: "-L", Type: "[]string", Description: "Add path to library search path"},
 	{Name: "HideInputPane", Flag: "hide-input-pane", Type: "bool", Description: "Hide input (left) viewing pane"},
+	{Name: "Watch", Flag: "watch", Type: "bool", Description: "Reload input files when they change"},
+	{Name: "Follow", Flag: "follow", Type: "bool", Description: "Follow values streamed on stdin"},
+	{Name: "FollowMode", Flag: "follow-mode", Type: "string", Description: "Follow mode", Values: []string{"all", "new"}},
+	{Name: "FollowLimit", Flag: "follow-limit", Type: "int", Description: "Retained values", Min: 1, Max: 1000000, Default: 10000, Step: 1000},
+	{Name: "OutputFormat", Flag: "output-format", Type: "string", Description: "Output format", Values: []string{"json", "yaml", "csv", "tsv", "table"}},
+	{Name: "InputFormat", Flag: "input-format", Type: "string", Description: "Input format", Values: []string{"json", "yaml", "toml", "csv", "tsv", "xml"}},
+	{Name: "Tabs", Flag: "tabs", Type: "bool", Description: "Show each file in its own tab"},
+	{Name: "TabScope", Flag: "tab-scope", Type: "string", Description: "Run filter on", Values: []string{"selected", "all"}},
 	{Name: "JQCommand", Flag: "jqbin", Type: "string", Description: "name of or path to jq binary to use"},
 	{Name: "HistoryFile", Flag: "H", Type: "string", Description: "set path to history file. Set to '' to disable history"},
+	{Name: "StringArgs", Flag: "arg", JQFlag: "--arg", Type: "[]NamedArg", Description: "Set variable to a string"},
+	{Name: "JSONArgs", Flag: "argjson", JQFlag: "--argjson", Type: "[]NamedArg", Description: "Set variable to a JSON value"},
+	{Name: "SlurpFiles", Flag: "slurpfile", JQFlag: "--slurpfile", Type: "[]NamedArg", Description: "Set variable to an array of JSON values read from a file"},
+	{Name: "RawFiles", Flag: "rawfile", JQFlag: "--rawfile", Type: "[]NamedArg", Description: "Set variable to the contents of a file"},
+	{Name: "PositionalArgs", Flag: "args", JQFlag: "--args", Type: "bool", Description: "Positional arguments are strings"},
+	{Name: "PositionalJSONArgs", Flag: "jsonargs", JQFlag: "--jsonargs", Type: "bool", Description: "Positional arguments are JSON"},
+	{Name: "Positional", Flag: "positional", Type: "[]string", Description: "Positional arguments"},
+	{Name: "Engine", Flag: "engine", Type: "string", Description: "Engine", Values: []string{"exec", "gojq"}},
 }
 
 const generatedTemplate = `// Code generated by go generate; DO NOT EDIT.
@@ -49,7 +84,7 @@ var (
 
 type (
 {{- range . }}
-	{{ .Name }} {{ .Type }}
+	{{ .Name }} {{ if eq .Type "int" }}IntValue{{ else }}{{ .Type }}{{ end }}
 {{- end }}
 )
 
@@ -68,6 +103,12 @@ func ({{ .Name }}) Flag() string {
 	return {{ printf "%q" .Flag }}
 }
 
+{{- if .JQFlag }}
+func ({{ .Name }}) JQFlag() string {
+	return {{ printf "%q" .JQFlag }}
+}
+{{- end }}
+
 {{- if eq .Type "bool" }}
 func (option *{{ .Name }}) Set(value string) error {
 	return setBoolOption(option, value)
@@ -77,6 +118,36 @@ func (option *{{ .Name }}) IsBoolFlag() bool {
 	return true
 }
 
+{{- else if eq .Type "int" }}
+func (option *{{ .Name }}) Set(value string) error {
+	return setIntOption(option.intValue(), value, {{ .Min }}, {{ .Max }})
+}
+
+func (option *{{ .Name }}) intValue() *IntValue {
+	return (*IntValue)(option)
+}
+
+func ({{ .Name }}) Range() (int, int) {
+	return {{ .Min }}, {{ .Max }}
+}
+
+func ({{ .Name }}) Default() int {
+	return {{ .Default }}
+}
+
+func ({{ .Name }}) Step() int {
+	return {{ or .Step 1 }}
+}
+
+{{- else if and (eq .Type "string") .Values }}
+func (option *{{ .Name }}) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func ({{ .Name }}) Values() []string {
+	return []string{ {{- range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ printf "%q" $v }}{{ end -}} }
+}
+
 {{- else if eq .Type "string" }}
 func (option *{{ .Name }}) Set(value string) error {
 	*option = {{ .Name }}(value)
@@ -88,6 +159,11 @@ func (option *{{ .Name }}) Set(value string) error {
 	*option = append(*option, value)
 	return nil
 }
+
+{{- else if eq .Type "[]NamedArg" }}
+func (option *{{ .Name }}) Set(value string) error {
+	return appendNamedArg(option, value)
+}
 {{- end }}
 
 func (option *{{ .Name }}) UnmarshalText(text []byte) error {
@@ -4,7 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 //go:generate go run gen.go
@@ -14,6 +16,39 @@ type Option interface {
 	Flag() string
 }
 
+// jqOption is implemented by options which are passed through to jq.
+type jqOption interface {
+	JQFlag() string
+}
+
+// intOption is implemented by options which hold a bounded integer.
+type intOption interface {
+	Range() (int, int)
+	Default() int
+	Step() int
+	intValue() *IntValue
+}
+
+// IntValue is the value of an integer option. An option which is not set
+// uses its default value, which 0 may differ from.
+type IntValue struct {
+	Value int
+	IsSet bool
+}
+
+// enumOption is implemented by options which hold one of a fixed set of
+// values. The first value is the default.
+type enumOption interface {
+	Values() []string
+}
+
+// NamedArg is a variable binding given to jq with --arg, --argjson,
+// --slurpfile or --rawfile.
+type NamedArg struct {
+	Name  string
+	Value string
+}
+
 func (o *Options) Toggle(option Option) {
 	optionType := reflect.TypeOf(option)
 	if optionType == nil {
@@ -26,20 +61,102 @@ func (o *Options) Toggle(option Option) {
 		}
 
 		value := reflect.ValueOf(opt).Elem()
-		if !value.IsValid() || value.Kind() != reflect.Bool {
+		if !value.IsValid() {
 			return
 		}
 
-		value.SetBool(!value.Bool())
+		switch value.Kind() {
+		case reflect.Bool:
+			value.SetBool(!value.Bool())
+		case reflect.String:
+			// Enum options cycle through their values
+			enum, ok := opt.(enumOption)
+			if !ok {
+				return
+			}
+
+			values := enum.Values()
+			i := slices.Index(values, EffectiveEnum(opt))
+			value.SetString(values[(i+1)%len(values)])
+		}
 	})
 }
 
+// EffectiveEnum returns the value of an enum option, or its default value if
+// it is unset.
+func EffectiveEnum(option Option) string {
+	enum, ok := option.(enumOption)
+	if !ok {
+		return ""
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(option))
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return ""
+	}
+
+	if value.String() == "" {
+		return enum.Values()[0]
+	}
+
+	return value.String()
+}
+
+// Adjust changes the value of an integer option by delta steps, keeping it
+// within the option's bounds. An unset option is adjusted starting from its default
+// value.
+func (o *Options) Adjust(option Option, delta int) {
+	optionType := reflect.TypeOf(option)
+	if optionType == nil {
+		return
+	}
+
+	o.Each(func(opt Option) {
+		if optionType != reflect.TypeOf(opt) {
+			return
+		}
+
+		bounded, ok := opt.(intOption)
+		if !ok {
+			return
+		}
+
+		lo, hi := bounded.Range()
+		next := min(max(EffectiveInt(opt)+delta*bounded.Step(), lo), hi)
+		*bounded.intValue() = IntValue{Value: next, IsSet: true}
+	})
+}
+
+// EffectiveInt returns the value of an integer option, or its default value
+// if it is unset.
+func EffectiveInt(option Option) int {
+	bounded, ok := option.(intOption)
+	if !ok {
+		return 0
+	}
+
+	if value := bounded.intValue(); value.IsSet {
+		return value.Value
+	}
+
+	return bounded.Default()
+}
+
 func (o Options) ToSlice() []string {
 	var flags []string
 
 	o.Each(func(option Option) {
-		flagName := option.Flag()
-		if len(flagName) != 1 {
+		jq, ok := option.(jqOption)
+		if !ok {
+			return
+		}
+
+		flagName := jq.JQFlag()
+
+		if bounded, ok := option.(intOption); ok {
+			if value := bounded.intValue(); value.IsSet {
+				flags = append(flags, flagName, strconv.Itoa(value.Value))
+			}
 			return
 		}
 
@@ -54,10 +171,15 @@ func (o Options) ToSlice() []string {
 				return
 			}
 
-			flags = append(flags, "-"+flagName)
+			flags = append(flags, flagName)
 		case reflect.Array, reflect.Slice:
 			for i := 0; i < value.Len(); i++ {
-				flags = append(flags, "-"+flagName, fmt.Sprint(value.Index(i).Interface()))
+				switch elem := value.Index(i).Interface().(type) {
+				case NamedArg:
+					flags = append(flags, flagName, elem.Name, elem.Value)
+				default:
+					flags = append(flags, flagName, fmt.Sprint(elem))
+				}
 			}
 		}
 	})
@@ -91,6 +213,40 @@ func setBoolOption[T ~bool](option *T, value string) error {
 	return nil
 }
 
+func setIntOption(option *IntValue, value string, lo int, hi int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	if n < lo || n > hi {
+		return fmt.Errorf("invalid value %d: must be between %d and %d", n, lo, hi)
+	}
+
+	*option = IntValue{Value: n, IsSet: true}
+	return nil
+}
+
+func setEnumOption[T ~string](option *T, value string, allowed ...string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("invalid value %q: must be one of %s", value, strings.Join(allowed, ", "))
+	}
+
+	*option = T(value)
+	return nil
+}
+
+// appendNamedArg parses a "name=value" pair and appends it to option.
+func appendNamedArg[T ~[]NamedArg](option *T, value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid argument %q: expected a name and a value", value)
+	}
+
+	*option = append(*option, NamedArg{Name: name, Value: val})
+	return nil
+}
+
 func unmarshalTextValue[T interface{ Set(string) error }](option T, text []byte) error {
 	return option.Set(string(text))
 }
@@ -25,48 +25,136 @@ var (
 	_ encoding.TextUnmarshaler = (*ForceColor)(nil)
 	_ Option                   = (*SortKeys)(nil)
 	_ encoding.TextUnmarshaler = (*SortKeys)(nil)
+	_ Option                   = (*Indent)(nil)
+	_ encoding.TextUnmarshaler = (*Indent)(nil)
+	_ Option                   = (*Tab)(nil)
+	_ encoding.TextUnmarshaler = (*Tab)(nil)
+	_ Option                   = (*Stream)(nil)
+	_ encoding.TextUnmarshaler = (*Stream)(nil)
+	_ Option                   = (*StreamErrors)(nil)
+	_ encoding.TextUnmarshaler = (*StreamErrors)(nil)
+	_ Option                   = (*Seq)(nil)
+	_ encoding.TextUnmarshaler = (*Seq)(nil)
+	_ Option                   = (*ExitStatus)(nil)
+	_ encoding.TextUnmarshaler = (*ExitStatus)(nil)
 	_ Option                   = (*LibraryPaths)(nil)
 	_ encoding.TextUnmarshaler = (*LibraryPaths)(nil)
 	_ Option                   = (*HideInputPane)(nil)
 	_ encoding.TextUnmarshaler = (*HideInputPane)(nil)
+	_ Option                   = (*Watch)(nil)
+	_ encoding.TextUnmarshaler = (*Watch)(nil)
+	_ Option                   = (*Follow)(nil)
+	_ encoding.TextUnmarshaler = (*Follow)(nil)
+	_ Option                   = (*FollowMode)(nil)
+	_ encoding.TextUnmarshaler = (*FollowMode)(nil)
+	_ Option                   = (*FollowLimit)(nil)
+	_ encoding.TextUnmarshaler = (*FollowLimit)(nil)
+	_ Option                   = (*OutputFormat)(nil)
+	_ encoding.TextUnmarshaler = (*OutputFormat)(nil)
+	_ Option                   = (*InputFormat)(nil)
+	_ encoding.TextUnmarshaler = (*InputFormat)(nil)
+	_ Option                   = (*Tabs)(nil)
+	_ encoding.TextUnmarshaler = (*Tabs)(nil)
+	_ Option                   = (*TabScope)(nil)
+	_ encoding.TextUnmarshaler = (*TabScope)(nil)
 	_ Option                   = (*JQCommand)(nil)
 	_ encoding.TextUnmarshaler = (*JQCommand)(nil)
 	_ Option                   = (*HistoryFile)(nil)
 	_ encoding.TextUnmarshaler = (*HistoryFile)(nil)
+	_ Option                   = (*StringArgs)(nil)
+	_ encoding.TextUnmarshaler = (*StringArgs)(nil)
+	_ Option                   = (*JSONArgs)(nil)
+	_ encoding.TextUnmarshaler = (*JSONArgs)(nil)
+	_ Option                   = (*SlurpFiles)(nil)
+	_ encoding.TextUnmarshaler = (*SlurpFiles)(nil)
+	_ Option                   = (*RawFiles)(nil)
+	_ encoding.TextUnmarshaler = (*RawFiles)(nil)
+	_ Option                   = (*PositionalArgs)(nil)
+	_ encoding.TextUnmarshaler = (*PositionalArgs)(nil)
+	_ Option                   = (*PositionalJSONArgs)(nil)
+	_ encoding.TextUnmarshaler = (*PositionalJSONArgs)(nil)
+	_ Option                   = (*Positional)(nil)
+	_ encoding.TextUnmarshaler = (*Positional)(nil)
+	_ Option                   = (*Engine)(nil)
+	_ encoding.TextUnmarshaler = (*Engine)(nil)
 )
 
 type (
-	CompactOutput bool
-	NullInput     bool
-	Slurp         bool
-	RawOutput     bool
-	JoinOutput    bool
-	ASCIIOutput   bool
-	RawInput      bool
-	Monochrome    bool
-	ForceColor    bool
-	SortKeys      bool
-	LibraryPaths  []string
-	HideInputPane bool
-	JQCommand     string
-	HistoryFile   string
+	CompactOutput      bool
+	NullInput          bool
+	Slurp              bool
+	RawOutput          bool
+	JoinOutput         bool
+	ASCIIOutput        bool
+	RawInput           bool
+	Monochrome         bool
+	ForceColor         bool
+	SortKeys           bool
+	Indent             IntValue
+	Tab                bool
+	Stream             bool
+	StreamErrors       bool
+	Seq                bool
+	ExitStatus         bool
+	LibraryPaths       []string
+	HideInputPane      bool
+	Watch              bool
+	Follow             bool
+	FollowMode         string
+	FollowLimit        IntValue
+	OutputFormat       string
+	InputFormat        string
+	Tabs               bool
+	TabScope           string
+	JQCommand          string
+	HistoryFile        string
+	StringArgs         []NamedArg
+	JSONArgs           []NamedArg
+	SlurpFiles         []NamedArg
+	RawFiles           []NamedArg
+	PositionalArgs     bool
+	PositionalJSONArgs bool
+	Positional         []string
+	Engine             string
 )
 
 type Options struct {
-	CompactOutput CompactOutput
-	NullInput     NullInput
-	Slurp         Slurp
-	RawOutput     RawOutput
-	JoinOutput    JoinOutput
-	ASCIIOutput   ASCIIOutput
-	RawInput      RawInput
-	Monochrome    Monochrome
-	ForceColor    ForceColor
-	SortKeys      SortKeys
-	LibraryPaths  LibraryPaths
-	HideInputPane HideInputPane
-	JQCommand     JQCommand
-	HistoryFile   HistoryFile
+	CompactOutput      CompactOutput
+	NullInput          NullInput
+	Slurp              Slurp
+	RawOutput          RawOutput
+	JoinOutput         JoinOutput
+	ASCIIOutput        ASCIIOutput
+	RawInput           RawInput
+	Monochrome         Monochrome
+	ForceColor         ForceColor
+	SortKeys           SortKeys
+	Indent             Indent
+	Tab                Tab
+	Stream             Stream
+	StreamErrors       StreamErrors
+	Seq                Seq
+	ExitStatus         ExitStatus
+	LibraryPaths       LibraryPaths
+	HideInputPane      HideInputPane
+	Watch              Watch
+	Follow             Follow
+	FollowMode         FollowMode
+	FollowLimit        FollowLimit
+	OutputFormat       OutputFormat
+	InputFormat        InputFormat
+	Tabs               Tabs
+	TabScope           TabScope
+	JQCommand          JQCommand
+	HistoryFile        HistoryFile
+	StringArgs         StringArgs
+	JSONArgs           JSONArgs
+	SlurpFiles         SlurpFiles
+	RawFiles           RawFiles
+	PositionalArgs     PositionalArgs
+	PositionalJSONArgs PositionalJSONArgs
+	Positional         Positional
+	Engine             Engine
 }
 
 func (CompactOutput) String() string {
@@ -76,6 +164,9 @@ func (CompactOutput) String() string {
 func (CompactOutput) Flag() string {
 	return "c"
 }
+func (CompactOutput) JQFlag() string {
+	return "-c"
+}
 func (option *CompactOutput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -95,6 +186,9 @@ func (NullInput) String() string {
 func (NullInput) Flag() string {
 	return "n"
 }
+func (NullInput) JQFlag() string {
+	return "-n"
+}
 func (option *NullInput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -114,6 +208,9 @@ func (Slurp) String() string {
 func (Slurp) Flag() string {
 	return "s"
 }
+func (Slurp) JQFlag() string {
+	return "-s"
+}
 func (option *Slurp) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -133,6 +230,9 @@ func (RawOutput) String() string {
 func (RawOutput) Flag() string {
 	return "r"
 }
+func (RawOutput) JQFlag() string {
+	return "-r"
+}
 func (option *RawOutput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -152,6 +252,9 @@ func (JoinOutput) String() string {
 func (JoinOutput) Flag() string {
 	return "j"
 }
+func (JoinOutput) JQFlag() string {
+	return "-j"
+}
 func (option *JoinOutput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -171,6 +274,9 @@ func (ASCIIOutput) String() string {
 func (ASCIIOutput) Flag() string {
 	return "a"
 }
+func (ASCIIOutput) JQFlag() string {
+	return "-a"
+}
 func (option *ASCIIOutput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -190,6 +296,9 @@ func (RawInput) String() string {
 func (RawInput) Flag() string {
 	return "R"
 }
+func (RawInput) JQFlag() string {
+	return "-R"
+}
 func (option *RawInput) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -209,6 +318,9 @@ func (Monochrome) String() string {
 func (Monochrome) Flag() string {
 	return "M"
 }
+func (Monochrome) JQFlag() string {
+	return "-M"
+}
 func (option *Monochrome) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -228,6 +340,9 @@ func (ForceColor) String() string {
 func (ForceColor) Flag() string {
 	return "C"
 }
+func (ForceColor) JQFlag() string {
+	return "-C"
+}
 func (option *ForceColor) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -247,6 +362,9 @@ func (SortKeys) String() string {
 func (SortKeys) Flag() string {
 	return "S"
 }
+func (SortKeys) JQFlag() string {
+	return "-S"
+}
 func (option *SortKeys) Set(value string) error {
 	return setBoolOption(option, value)
 }
@@ -259,6 +377,150 @@ func (option *SortKeys) UnmarshalText(text []byte) error {
 	return unmarshalTextValue(option, text)
 }
 
+func (Indent) String() string {
+	return "Indentation"
+}
+
+func (Indent) Flag() string {
+	return "indent"
+}
+func (Indent) JQFlag() string {
+	return "--indent"
+}
+func (option *Indent) Set(value string) error {
+	return setIntOption(option.intValue(), value, 0, 7)
+}
+
+func (option *Indent) intValue() *IntValue {
+	return (*IntValue)(option)
+}
+
+func (Indent) Range() (int, int) {
+	return 0, 7
+}
+
+func (Indent) Default() int {
+	return 2
+}
+
+func (Indent) Step() int {
+	return 1
+}
+
+func (option *Indent) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Tab) String() string {
+	return "Indent with tabs"
+}
+
+func (Tab) Flag() string {
+	return "tab"
+}
+func (Tab) JQFlag() string {
+	return "--tab"
+}
+func (option *Tab) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Tab) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Tab) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Stream) String() string {
+	return "Parse input in streaming fashion"
+}
+
+func (Stream) Flag() string {
+	return "stream"
+}
+func (Stream) JQFlag() string {
+	return "--stream"
+}
+func (option *Stream) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Stream) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Stream) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (StreamErrors) String() string {
+	return "Stream input and report parse errors as values"
+}
+
+func (StreamErrors) Flag() string {
+	return "stream-errors"
+}
+func (StreamErrors) JQFlag() string {
+	return "--stream-errors"
+}
+func (option *StreamErrors) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *StreamErrors) IsBoolFlag() bool {
+	return true
+}
+
+func (option *StreamErrors) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Seq) String() string {
+	return "Use application/json-seq records"
+}
+
+func (Seq) Flag() string {
+	return "seq"
+}
+func (Seq) JQFlag() string {
+	return "--seq"
+}
+func (option *Seq) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Seq) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Seq) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (ExitStatus) String() string {
+	return "Set exit status from the last output"
+}
+
+func (ExitStatus) Flag() string {
+	return "e"
+}
+func (ExitStatus) JQFlag() string {
+	return "-e"
+}
+func (option *ExitStatus) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *ExitStatus) IsBoolFlag() bool {
+	return true
+}
+
+func (option *ExitStatus) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
 func (LibraryPaths) String() string {
 	return "Add path to library search path"
 }
@@ -266,6 +528,9 @@ func (LibraryPaths) String() string {
 func (LibraryPaths) Flag() string {
 	return "L"
 }
+func (LibraryPaths) JQFlag() string {
+	return "-L"
+}
 func (option *LibraryPaths) Set(value string) error {
 	*option = append(*option, value)
 	return nil
@@ -294,6 +559,170 @@ func (option *HideInputPane) UnmarshalText(text []byte) error {
 	return unmarshalTextValue(option, text)
 }
 
+func (Watch) String() string {
+	return "Reload input files when they change"
+}
+
+func (Watch) Flag() string {
+	return "watch"
+}
+func (option *Watch) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Watch) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Watch) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Follow) String() string {
+	return "Follow values streamed on stdin"
+}
+
+func (Follow) Flag() string {
+	return "follow"
+}
+func (option *Follow) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Follow) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Follow) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (FollowMode) String() string {
+	return "Follow mode"
+}
+
+func (FollowMode) Flag() string {
+	return "follow-mode"
+}
+func (option *FollowMode) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func (FollowMode) Values() []string {
+	return []string{"all", "new"}
+}
+
+func (option *FollowMode) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (FollowLimit) String() string {
+	return "Retained values"
+}
+
+func (FollowLimit) Flag() string {
+	return "follow-limit"
+}
+func (option *FollowLimit) Set(value string) error {
+	return setIntOption(option.intValue(), value, 1, 1000000)
+}
+
+func (option *FollowLimit) intValue() *IntValue {
+	return (*IntValue)(option)
+}
+
+func (FollowLimit) Range() (int, int) {
+	return 1, 1000000
+}
+
+func (FollowLimit) Default() int {
+	return 10000
+}
+
+func (FollowLimit) Step() int {
+	return 1000
+}
+
+func (option *FollowLimit) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (OutputFormat) String() string {
+	return "Output format"
+}
+
+func (OutputFormat) Flag() string {
+	return "output-format"
+}
+func (option *OutputFormat) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func (OutputFormat) Values() []string {
+	return []string{"json", "yaml", "csv", "tsv", "table"}
+}
+
+func (option *OutputFormat) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (InputFormat) String() string {
+	return "Input format"
+}
+
+func (InputFormat) Flag() string {
+	return "input-format"
+}
+func (option *InputFormat) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func (InputFormat) Values() []string {
+	return []string{"json", "yaml", "toml", "csv", "tsv", "xml"}
+}
+
+func (option *InputFormat) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Tabs) String() string {
+	return "Show each file in its own tab"
+}
+
+func (Tabs) Flag() string {
+	return "tabs"
+}
+func (option *Tabs) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *Tabs) IsBoolFlag() bool {
+	return true
+}
+
+func (option *Tabs) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (TabScope) String() string {
+	return "Run filter on"
+}
+
+func (TabScope) Flag() string {
+	return "tab-scope"
+}
+func (option *TabScope) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func (TabScope) Values() []string {
+	return []string{"selected", "all"}
+}
+
+func (option *TabScope) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
 func (JQCommand) String() string {
 	return "name of or path to jq binary to use"
 }
@@ -325,3 +754,154 @@ func (option *HistoryFile) Set(value string) error {
 func (option *HistoryFile) UnmarshalText(text []byte) error {
 	return unmarshalTextValue(option, text)
 }
+
+func (StringArgs) String() string {
+	return "Set variable to a string"
+}
+
+func (StringArgs) Flag() string {
+	return "arg"
+}
+func (StringArgs) JQFlag() string {
+	return "--arg"
+}
+func (option *StringArgs) Set(value string) error {
+	return appendNamedArg(option, value)
+}
+
+func (option *StringArgs) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (JSONArgs) String() string {
+	return "Set variable to a JSON value"
+}
+
+func (JSONArgs) Flag() string {
+	return "argjson"
+}
+func (JSONArgs) JQFlag() string {
+	return "--argjson"
+}
+func (option *JSONArgs) Set(value string) error {
+	return appendNamedArg(option, value)
+}
+
+func (option *JSONArgs) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (SlurpFiles) String() string {
+	return "Set variable to an array of JSON values read from a file"
+}
+
+func (SlurpFiles) Flag() string {
+	return "slurpfile"
+}
+func (SlurpFiles) JQFlag() string {
+	return "--slurpfile"
+}
+func (option *SlurpFiles) Set(value string) error {
+	return appendNamedArg(option, value)
+}
+
+func (option *SlurpFiles) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (RawFiles) String() string {
+	return "Set variable to the contents of a file"
+}
+
+func (RawFiles) Flag() string {
+	return "rawfile"
+}
+func (RawFiles) JQFlag() string {
+	return "--rawfile"
+}
+func (option *RawFiles) Set(value string) error {
+	return appendNamedArg(option, value)
+}
+
+func (option *RawFiles) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (PositionalArgs) String() string {
+	return "Positional arguments are strings"
+}
+
+func (PositionalArgs) Flag() string {
+	return "args"
+}
+func (PositionalArgs) JQFlag() string {
+	return "--args"
+}
+func (option *PositionalArgs) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *PositionalArgs) IsBoolFlag() bool {
+	return true
+}
+
+func (option *PositionalArgs) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (PositionalJSONArgs) String() string {
+	return "Positional arguments are JSON"
+}
+
+func (PositionalJSONArgs) Flag() string {
+	return "jsonargs"
+}
+func (PositionalJSONArgs) JQFlag() string {
+	return "--jsonargs"
+}
+func (option *PositionalJSONArgs) Set(value string) error {
+	return setBoolOption(option, value)
+}
+
+func (option *PositionalJSONArgs) IsBoolFlag() bool {
+	return true
+}
+
+func (option *PositionalJSONArgs) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Positional) String() string {
+	return "Positional arguments"
+}
+
+func (Positional) Flag() string {
+	return "positional"
+}
+func (option *Positional) Set(value string) error {
+	*option = append(*option, value)
+	return nil
+}
+
+func (option *Positional) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
+
+func (Engine) String() string {
+	return "Engine"
+}
+
+func (Engine) Flag() string {
+	return "engine"
+}
+func (option *Engine) Set(value string) error {
+	return setEnumOption(option, value, option.Values()...)
+}
+
+func (Engine) Values() []string {
+	return []string{"exec", "gojq"}
+}
+
+func (option *Engine) UnmarshalText(text []byte) error {
+	return unmarshalTextValue(option, text)
+}
@@ -30,6 +30,34 @@ func TestOptionsToSliceIncludesLibraryPaths(t *testing.T) {
 	assert.Equal(t, []string{"-c", "-L", "foo", "-L", "bar"}, opts.ToSlice())
 }
 
+func TestOptionsToSliceIncludesStreamFlags(t *testing.T) {
+	opts := Options{
+		Stream: true,
+		Seq:    true,
+	}
+
+	assert.Equal(t, []string{"--stream", "--seq"}, opts.ToSlice())
+}
+
+func TestOptionsToSliceIncludesNamedArgs(t *testing.T) {
+	opts := Options{
+		StringArgs:     StringArgs{{Name: "foo", Value: "bar baz"}},
+		JSONArgs:       JSONArgs{{Name: "n", Value: "1"}},
+		SlurpFiles:     SlurpFiles{{Name: "data", Value: "data.json"}},
+		RawFiles:       RawFiles{{Name: "text", Value: "notes.txt"}},
+		PositionalArgs: true,
+		Positional:     Positional{"a", "b"},
+	}
+
+	assert.Equal(t, []string{
+		"--arg", "foo", "bar baz",
+		"--argjson", "n", "1",
+		"--slurpfile", "data", "data.json",
+		"--rawfile", "text", "notes.txt",
+		"--args",
+	}, opts.ToSlice())
+}
+
 func TestOptionsToSliceExcludesNonRuntimeFlags(t *testing.T) {
 	opts := Options{
 		HideInputPane: true,
@@ -60,6 +88,87 @@ func TestSetOnLibraryPathsOption(t *testing.T) {
 	assert.EqualValues(t, []string{"foo", "bar"}, paths)
 }
 
+func TestSetOnEnumOption(t *testing.T) {
+	var engine Engine
+
+	assert.NoError(t, engine.Set("gojq"))
+	assert.EqualValues(t, "gojq", engine)
+
+	assert.Error(t, engine.Set("jaq"))
+	assert.EqualValues(t, "gojq", engine)
+}
+
+func TestSetOnNamedArgOption(t *testing.T) {
+	var args StringArgs
+
+	assert.NoError(t, args.Set("foo=bar=baz"))
+	assert.NoError(t, args.Set("empty="))
+	assert.Equal(t, StringArgs{{Name: "foo", Value: "bar=baz"}, {Name: "empty", Value: ""}}, args)
+
+	assert.Error(t, args.Set("novalue"))
+	assert.Error(t, args.Set("=value"))
+}
+
+func TestSetOnIntOption(t *testing.T) {
+	var indent Indent
+
+	assert.NoError(t, indent.Set("4"))
+	assert.Equal(t, Indent{Value: 4, IsSet: true}, indent)
+
+	assert.Error(t, indent.Set("-1"))
+	assert.Error(t, indent.Set("8"))
+	assert.Error(t, indent.Set("two"))
+	assert.Equal(t, Indent{Value: 4, IsSet: true}, indent)
+
+	// 0 is a valid indentation, which is not the same as leaving it unset
+	assert.NoError(t, indent.Set("0"))
+	assert.Equal(t, Indent{Value: 0, IsSet: true}, indent)
+	assert.Equal(t, 0, EffectiveInt(&indent))
+	assert.Equal(t, 2, EffectiveInt(new(Indent)))
+}
+
+func TestOptionsToSliceIncludesIndent(t *testing.T) {
+	opts := Options{Indent: Indent{Value: 4, IsSet: true}, Tab: true}
+	assert.Equal(t, []string{"--indent", "4", "--tab"}, opts.ToSlice())
+
+	opts = Options{}
+	assert.NoError(t, opts.Indent.Set("0"))
+	assert.Equal(t, []string{"--indent", "0"}, opts.ToSlice())
+}
+
+func TestAdjustIntOption(t *testing.T) {
+	var opts Options
+
+	opts.Adjust(&opts.Indent, 1)
+	assert.Equal(t, Indent{Value: 3, IsSet: true}, opts.Indent)
+
+	opts.Adjust(&opts.Indent, -5)
+	assert.Equal(t, 0, EffectiveInt(&opts.Indent))
+	assert.Equal(t, []string{"--indent", "0"}, opts.ToSlice())
+
+	opts.Adjust(&opts.Indent, 1)
+	assert.Equal(t, Indent{Value: 1, IsSet: true}, opts.Indent)
+
+	opts.Indent = Indent{Value: 7, IsSet: true}
+	opts.Adjust(&opts.Indent, 1)
+	assert.Equal(t, Indent{Value: 7, IsSet: true}, opts.Indent)
+
+	assert.NotPanics(t, func() {
+		opts.Adjust(&opts.CompactOutput, 1)
+	})
+	assert.False(t, bool(opts.CompactOutput))
+}
+
+func TestAdjustIntOptionUsesStep(t *testing.T) {
+	var opts Options
+
+	opts.Adjust(&opts.FollowLimit, 1)
+	assert.Equal(t, FollowLimit{Value: 11000, IsSet: true}, opts.FollowLimit)
+
+	opts.Adjust(&opts.FollowLimit, -20)
+	assert.Equal(t, FollowLimit{Value: 1, IsSet: true}, opts.FollowLimit)
+}
+
 func TestToggleDoesNotPanicForNonBoolOptions(t *testing.T) {
 	opts := Options{
 		CompactOutput: true,
@@ -75,3 +184,17 @@ func TestToggleDoesNotPanicForNonBoolOptions(t *testing.T) {
 	opts.Toggle(&opts.CompactOutput)
 	assert.False(t, bool(opts.CompactOutput))
 }
+
+func TestToggleCyclesEnumOptions(t *testing.T) {
+	var opts Options
+	assert.Equal(t, "json", EffectiveEnum(&opts.OutputFormat))
+
+	for _, want := range []string{"yaml", "csv", "tsv", "table", "json"} {
+		opts.Toggle(&opts.OutputFormat)
+		assert.EqualValues(t, want, opts.OutputFormat)
+	}
+
+	opts.JQCommand = "jq"
+	opts.Toggle(&opts.JQCommand)
+	assert.EqualValues(t, "jq", opts.JQCommand)
+}
@@ -3,6 +3,9 @@ package overlay
 import (
 	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/rivo/tview"
 
 	"codeberg.org/gpanders/ijq/internal/options"
 )
@@ -12,19 +15,55 @@ func ConfigureRows(opts options.Options) []string {
 	var rows []string
 	opts.Each(func(opt options.Option) {
 		value := reflect.ValueOf(opt).Elem()
-		if value.Kind() == reflect.Bool {
+		switch value.Kind() {
+		case reflect.Bool:
 			checkbox := "○"
 			if value.Bool() {
 				checkbox = "●"
 			}
 
 			rows = append(rows, fmt.Sprintf("%s %s (-%s)", checkbox, opt, opt.Flag()))
+		case reflect.Struct:
+			if isInt(opt) {
+				rows = append(rows, fmt.Sprintf("± %s: %d (-%s)", opt, options.EffectiveInt(opt), opt.Flag()))
+			}
+		case reflect.String:
+			if isEnum(opt) {
+				rows = append(rows, fmt.Sprintf("↻ %s: %s (-%s)", opt, options.EffectiveEnum(opt), opt.Flag()))
+			}
+		}
+	})
+
+	// Named arguments cannot be changed from the UI, but list them after
+	// the toggles so that the variables available to the filter are visible
+	opts.Each(func(opt options.Option) {
+		value := reflect.ValueOf(opt).Elem()
+		if value.Kind() != reflect.Slice || value.Type().Elem() != reflect.TypeFor[options.NamedArg]() {
+			return
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			arg := value.Index(i).Interface().(options.NamedArg)
+			rows = append(rows, fmt.Sprintf("  $%s = %s (-%s)", tview.Escape(arg.Name), tview.Escape(truncate(arg.Value, maxArgWidth)), opt.Flag()))
 		}
 	})
 
 	return rows
 }
 
+// Maximum number of characters of a named argument's value shown in the
+// Configure window
+const maxArgWidth = 30
+
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+
+	return s
+}
+
 var configureRows = func() []options.Option {
 	var o options.Options
 
@@ -35,16 +74,33 @@ var configureRows = func() []options.Option {
 			return
 		}
 
-		if value.Kind() != reflect.Bool {
-			return
+		switch value.Kind() {
+		case reflect.Bool:
+			rows = append(rows, opt)
+		case reflect.Struct:
+			if isInt(opt) {
+				rows = append(rows, opt)
+			}
+		case reflect.String:
+			if isEnum(opt) {
+				rows = append(rows, opt)
+			}
 		}
-
-		rows = append(rows, opt)
 	})
 
 	return rows
 }()
 
+func isEnum(opt options.Option) bool {
+	_, ok := opt.(interface{ Values() []string })
+	return ok
+}
+
+func isInt(opt options.Option) bool {
+	_, ok := opt.(interface{ Range() (int, int) })
+	return ok
+}
+
 var configureSize = func() struct {
 	Width  int
 	Height int
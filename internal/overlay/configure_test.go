@@ -2,6 +2,7 @@ package overlay
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,18 +10,34 @@ import (
 	"codeberg.org/gpanders/ijq/internal/options"
 )
 
-func TestConfigureRowsContainsOnlyBoolOptionsInStructOrder(t *testing.T) {
+func TestConfigureRowsContainsOnlyBoolIntAndEnumOptionsInStructOrder(t *testing.T) {
 	flags := make([]string, 0, len(configureRows))
 	for _, option := range configureRows {
 		value := reflect.Indirect(reflect.ValueOf(option))
 		if assert.True(t, value.IsValid()) {
-			assert.Equal(t, reflect.Bool, value.Kind())
+			assert.Contains(t, []reflect.Kind{reflect.Bool, reflect.Struct, reflect.String}, value.Kind())
 		}
 
 		flags = append(flags, option.Flag())
 	}
 
-	assert.Equal(t, []string{"c", "n", "s", "r", "j", "a", "R", "M", "C", "S", "hide-input-pane"}, flags)
+	assert.Equal(t, []string{"c", "n", "s", "r", "j", "a", "R", "M", "C", "S", "indent", "tab", "stream", "stream-errors", "seq", "e", "hide-input-pane", "watch", "follow", "follow-mode", "follow-limit", "output-format", "input-format", "tabs", "tab-scope", "args", "jsonargs", "engine"}, flags)
+}
+
+func TestConfigureRowsShowsIntValues(t *testing.T) {
+	rows := ConfigureRows(options.Options{})
+	assert.Contains(t, rows, "± Indentation: 2 (-indent)")
+
+	rows = ConfigureRows(options.Options{Indent: options.Indent{Value: 4, IsSet: true}})
+	assert.Contains(t, rows, "± Indentation: 4 (-indent)")
+}
+
+func TestConfigureRowsShowsEnumValues(t *testing.T) {
+	rows := ConfigureRows(options.Options{})
+	assert.Contains(t, rows, "↻ Output format: json (-output-format)")
+
+	rows = ConfigureRows(options.Options{OutputFormat: "table"})
+	assert.Contains(t, rows, "↻ Output format: table (-output-format)")
 }
 
 func TestConfigureRowsExcludesNonBoolOptions(t *testing.T) {
@@ -28,6 +45,8 @@ func TestConfigureRowsExcludesNonBoolOptions(t *testing.T) {
 		assert.NotEqual(t, "L", option.Flag())
 		assert.NotEqual(t, "jqbin", option.Flag())
 		assert.NotEqual(t, "H", option.Flag())
+		assert.NotEqual(t, "arg", option.Flag())
+		assert.NotEqual(t, "argjson", option.Flag())
 	}
 }
 
@@ -40,3 +59,16 @@ func TestConfigureSizeMatchesConfigureRows(t *testing.T) {
 	assert.Equal(t, maxWidth, configureSize.Width)
 	assert.Equal(t, len(configureRows)+1, configureSize.Height)
 }
+
+func TestConfigureRowsListsNamedArgs(t *testing.T) {
+	opts := options.Options{
+		StringArgs: options.StringArgs{{Name: "name", Value: "value"}},
+		JSONArgs:   options.JSONArgs{{Name: "obj", Value: `{"a":[1]}`}},
+		RawFiles:   options.RawFiles{{Name: "text", Value: strings.Repeat("x", 40)}},
+	}
+
+	rows := ConfigureRows(opts)
+	assert.Equal(t, "  $name = value (-arg)", rows[len(rows)-3])
+	assert.Equal(t, `  $obj = {"a":[1[]} (-argjson)`, rows[len(rows)-2])
+	assert.Equal(t, "  $text = "+strings.Repeat("x", 29)+"… (-rawfile)", rows[len(rows)-1])
+}
@@ -2,6 +2,7 @@ package overlay
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -17,10 +18,21 @@ const (
 	confirmDeletePage = "overlay-confirm-delete"
 	cheatSheetPage    = "overlay-cheat-sheet"
 	keybindingsPage   = "overlay-keybindings"
+	snapshotsPage     = "overlay-snapshots"
+	snapshotNamePage  = "overlay-snapshot-name"
+	saveOutputPage    = "overlay-save-output"
 
 	smallWidth    = 50
 	menuHeight    = 10
 	historyHeight = 15
+
+	// The name prompt is a single bordered line above the help text, which
+	// is 2 rows fewer than resize adds to its height
+	snapshotNameHeight = 2
+
+	// The path and the options are shown in a bordered box above the help
+	// text
+	saveOutputHeight = 3
 )
 
 type mode int
@@ -33,11 +45,15 @@ const (
 	modeHistoryConfirmDelete
 	modeCheatSheet
 	modeKeybindings
+	modeSnapshotList
+	modeSnapshotName
+	modeSaveOutput
+	modeSaveConfirmOverwrite
 )
 
 func (m mode) IsTextInput() bool {
 	switch m {
-	case modeHistoryFilter:
+	case modeHistoryFilter, modeSnapshotName, modeSaveOutput:
 		return true
 	default:
 		return false
@@ -47,12 +63,17 @@ func (m mode) IsTextInput() bool {
 const (
 	historyHelpText    = "[::d]Enter[::-] [::b]select[::-]   [::d]/[::-] [::b]filter[::-]   [::d]X[::-] [::b]delete[::-]"
 	rootHelpText       = "[::d]Esc/Ctrl-C[::-] [::b]close[::-]   [::d]Space/Enter[::-] [::b]select[::-]"
-	configureHelpText  = "[::d]Space/Enter[::-] [::b]toggle[::-]"
+	configureHelpText  = "[::d]Space/Enter[::-] [::b]toggle[::-]   [::d]+/-[::-] [::b]adjust[::-]"
 	cheatSheetHelpText = "[::d]Esc/Ctrl-C[::-] [::b]close[::-]"
 	keybindHelpText    = "[::d]Esc/Ctrl-C[::-] [::b]close[::-]"
+	snapshotsHelpText  = "[::d]Enter[::-] [::b]show[::-]   [::d]p[::-] [::b]pin[::-]   [::d]X[::-] [::b]delete[::-]"
+	snapshotNameHelp   = "[::d]Enter[::-] [::b]pin[::-]   [::d]Esc[::-] [::b]cancel[::-]"
+	saveOutputHelpText = "[::d]Tab[::-] [::b]complete[::-]   [::d]Enter[::-] [::b]save[::-]   [::d]Esc[::-] [::b]cancel[::-]"
 
 	confirmDeletePromptText = "Delete the following entry from history?"
 	confirmDeleteHeight     = 5
+
+	confirmOverwritePromptText = "Overwrite the following file?"
 )
 
 type KeybindingEntry struct {
@@ -60,14 +81,30 @@ type KeybindingEntry struct {
 	Keybinding string
 }
 
+// SnapshotEntry is a pinned filter and its output as listed in the overlay.
+type SnapshotEntry struct {
+	Name   string
+	Filter string
+}
+
 type Callbacks struct {
 	ConfigureRows              func() []string
 	ToggleConfigureRow         func(option options.Option)
+	AdjustConfigureRow         func(option options.Option, delta int)
 	SaveCurrentFilterToHistory func() (status string, err error)
 	LoadHistoryEntries         func() []string
 	DeleteHistoryEntryAt       func(index int) error
 	ApplyHistoryEntry          func(expr string)
 	ActiveKeybindings          func() []KeybindingEntry
+	PinSnapshot                func(name string) error
+	LoadSnapshots              func() []SnapshotEntry
+	ShowSnapshot               func(index int)
+	DeleteSnapshotAt           func(index int) error
+
+	// SaveOutput starts saving the output to path and returns a function
+	// which cancels it. done is called from the event loop once it is
+	// saved, unless it is cancelled.
+	SaveOutput func(path string, opts SaveOptions, done func(error)) (cancel func())
 }
 
 type Controller struct {
@@ -85,21 +122,32 @@ type Controller struct {
 	history    *tview.List
 	cheatSheet *tview.TextView
 	keybinds   *tview.TextView
+	snapshots  *tview.List
 
 	rootLayout       *tview.Flex
 	configureLayout  *tview.Flex
 	historyLayout    *tview.Flex
 	cheatSheetLayout *tview.Flex
 	keybindsLayout   *tview.Flex
+	snapshotsLayout  *tview.Flex
+	snapshotLayout   *tview.Flex
+	saveOutputLayout *tview.Flex
+	saveOutputBox    *tview.Flex
 
 	rootHelpTextView       *tview.TextView
 	configureHelpTextView  *tview.TextView
 	historyHelpTextView    *tview.TextView
 	cheatSheetHelpTextView *tview.TextView
 	keybindHelpTextView    *tview.TextView
+	snapshotsHelpTextView  *tview.TextView
+	snapshotNameHelpView   *tview.TextView
+	saveOutputHelpTextView *tview.TextView
 
 	historyFilterInput *tview.InputField
 	confirmDeleteView  *tview.TextView
+	snapshotNameInput  *tview.InputField
+	savePathInput      *tview.InputField
+	saveOptionsView    *tview.TextView
 
 	open          bool
 	mode          mode
@@ -113,7 +161,26 @@ type Controller struct {
 
 	pendingDeleteIndex int
 	pendingDeleteEntry string
-	confirmDeleteYes   bool
+	confirmYes         bool
+
+	snapshotEntries []SnapshotEntry
+
+	// Whether the name prompt returns to the list of snapshots when it is
+	// cancelled, rather than closing the overlay
+	pinFromList bool
+
+	// The options the output is saved with, which are kept while ijq
+	// runs, and the path of a file waiting to be overwritten
+	saveOptions     SaveOptions
+	pendingSavePath string
+
+	// The output being saved, if any
+	saving *pendingSave
+}
+
+// pendingSave is the output being saved in the background.
+type pendingSave struct {
+	cancel func()
 }
 
 func NewController(app *tview.Application, pages *tview.Pages, pageName string, callbacks Callbacks) *Controller {
@@ -131,6 +198,8 @@ func NewController(app *tview.Application, pages *tview.Pages, pageName string,
 	c.rootMenu.AddItem("Manage history", "", 0, nil)
 	c.rootMenu.AddItem("Keybindings", "", 0, nil)
 	c.rootMenu.AddItem("Cheat sheet", "", 0, nil)
+	c.rootMenu.AddItem("Pinned snapshots", "", 0, nil)
+	c.rootMenu.AddItem("Save output as…", "", 0, nil)
 
 	c.configure = newList("Configure")
 	c.configure.SetUseStyleTags(true, false)
@@ -138,6 +207,16 @@ func NewController(app *tview.Application, pages *tview.Pages, pageName string,
 	c.configure.SetBorderPadding(0, 0, 1, 1)
 
 	c.history = newList("History")
+	c.snapshots = newList("Snapshots")
+
+	c.snapshotNameInput = tview.NewInputField()
+	c.snapshotNameInput.SetFieldBackgroundColor(tcell.ColorDefault)
+	c.snapshotNameInput.SetFieldTextColor(tcell.ColorDefault)
+	c.snapshotNameInput.SetLabel("Name: ")
+	c.snapshotNameInput.SetPlaceholder("optional")
+	c.snapshotNameInput.SetBorder(true)
+	c.snapshotNameInput.SetTitle("Pin snapshot")
+	c.snapshotNameInput.SetBorderPadding(0, 0, 1, 1)
 
 	c.historyFilterInput = tview.NewInputField()
 	c.historyFilterInput.SetFieldBackgroundColor(tcell.ColorDefault)
@@ -171,6 +250,14 @@ func NewController(app *tview.Application, pages *tview.Pages, pageName string,
 	c.confirmDeleteView.SetDynamicColors(true)
 	c.confirmDeleteView.SetBorderPadding(0, 0, 1, 1)
 
+	c.savePathInput = tview.NewInputField()
+	c.savePathInput.SetFieldBackgroundColor(tcell.ColorDefault)
+	c.savePathInput.SetFieldTextColor(tcell.ColorDefault)
+	c.savePathInput.SetLabel("Path: ")
+
+	c.saveOptionsView = tview.NewTextView()
+	c.saveOptionsView.SetDynamicColors(true)
+
 	c.cheatSheet = tview.NewTextView()
 	c.cheatSheet.SetBorder(true)
 	c.cheatSheet.SetTitle("jq cheat sheet")
@@ -196,6 +283,21 @@ func NewController(app *tview.Application, pages *tview.Pages, pageName string,
 	c.keybindHelpTextView.SetTextAlign(tview.AlignCenter)
 	c.keybindHelpTextView.SetText(keybindHelpText)
 
+	c.snapshotsHelpTextView = tview.NewTextView()
+	c.snapshotsHelpTextView.SetDynamicColors(true)
+	c.snapshotsHelpTextView.SetTextAlign(tview.AlignCenter)
+	c.snapshotsHelpTextView.SetText(snapshotsHelpText)
+
+	c.snapshotNameHelpView = tview.NewTextView()
+	c.snapshotNameHelpView.SetDynamicColors(true)
+	c.snapshotNameHelpView.SetTextAlign(tview.AlignCenter)
+	c.snapshotNameHelpView.SetText(snapshotNameHelp)
+
+	c.saveOutputHelpTextView = tview.NewTextView()
+	c.saveOutputHelpTextView.SetDynamicColors(true)
+	c.saveOutputHelpTextView.SetTextAlign(tview.AlignCenter)
+	c.saveOutputHelpTextView.SetText(saveOutputHelpText)
+
 	c.rootLayout = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(c.rootMenu, 0, 1, true).
@@ -222,13 +324,38 @@ func NewController(app *tview.Application, pages *tview.Pages, pageName string,
 		AddItem(c.keybinds, 0, 1, true).
 		AddItem(c.keybindHelpTextView, 1, 0, false)
 
+	c.snapshotsLayout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(c.snapshots, 0, 1, true).
+		AddItem(c.snapshotsHelpTextView, 1, 0, false)
+
+	c.snapshotLayout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(c.snapshotNameInput, 0, 1, true).
+		AddItem(c.snapshotNameHelpView, 1, 0, false)
+
+	c.saveOutputBox = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(c.savePathInput, 1, 0, true).
+		AddItem(c.saveOptionsView, 1, 0, false)
+	c.saveOutputBox.SetBorder(true)
+	c.saveOutputBox.SetBorderPadding(0, 0, 1, 1)
+
+	c.saveOutputLayout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(c.saveOutputBox, 0, 1, true).
+		AddItem(c.saveOutputHelpTextView, 1, 0, false)
+
 	c.subpages = tview.NewPages().
 		AddPage(rootMenuPage, c.rootLayout, true, true).
 		AddPage(configurePage, c.configureLayout, true, false).
 		AddPage(historyPage, c.historyLayout, true, false).
 		AddPage(confirmDeletePage, c.confirmDeleteView, true, false).
 		AddPage(keybindingsPage, c.keybindsLayout, true, false).
-		AddPage(cheatSheetPage, c.cheatSheetLayout, true, false)
+		AddPage(cheatSheetPage, c.cheatSheetLayout, true, false).
+		AddPage(snapshotsPage, c.snapshotsLayout, true, false).
+		AddPage(snapshotNamePage, c.snapshotLayout, true, false).
+		AddPage(saveOutputPage, c.saveOutputLayout, true, false)
 
 	c.container = tview.NewGrid().
 		SetRows(0, menuHeight, 0).
@@ -258,6 +385,19 @@ func (c *Controller) Open() {
 	c.showRootMenu("")
 }
 
+// IsTextInput reports whether the overlay is open and text is being entered
+// in it, so that keys should not be remapped.
+func (c *Controller) IsTextInput() bool {
+	return c.open && c.mode.IsTextInput()
+}
+
+// OpenPinPrompt opens the overlay to ask for the name of a snapshot of the
+// current filter and its output.
+func (c *Controller) OpenPinPrompt() {
+	c.Open()
+	c.showSnapshotName(false)
+}
+
 func (c *Controller) Close() {
 	if !c.open {
 		return
@@ -265,6 +405,7 @@ func (c *Controller) Close() {
 
 	c.pages.HidePage(c.pageName)
 	c.open = false
+	c.cancelSave()
 
 	if c.previousFocus != nil {
 		c.app.SetFocus(c.previousFocus)
@@ -321,6 +462,12 @@ func (c *Controller) HandleInput(event *tcell.EventKey) *tcell.EventKey {
 				case ' ':
 					c.toggleConfigure(c.configure.GetCurrentItem())
 					return nil
+				case '+', '=':
+					c.adjustConfigure(c.configure.GetCurrentItem(), 1)
+					return nil
+				case '-', '_':
+					c.adjustConfigure(c.configure.GetCurrentItem(), -1)
+					return nil
 				}
 			}
 		case tcell.KeyEnter:
@@ -330,35 +477,35 @@ func (c *Controller) HandleInput(event *tcell.EventKey) *tcell.EventKey {
 			c.showRootMenu("")
 			return nil
 		}
-	case modeHistoryConfirmDelete:
+	case modeHistoryConfirmDelete, modeSaveConfirmOverwrite:
 		switch event.Key() {
 		case tcell.KeyEnter:
-			c.confirmDeleteSelection(c.confirmDeleteYes)
+			c.confirmSelection(c.confirmYes)
 			return nil
 		case tcell.KeyEsc, tcell.KeyCtrlC:
-			c.confirmDeleteSelection(false)
+			c.confirmSelection(false)
 			return nil
 
 		case tcell.KeyLeft, tcell.KeyBacktab:
-			c.confirmDeleteYes = true
-			c.renderConfirmDeletePrompt()
+			c.confirmYes = true
+			c.renderConfirmPrompt()
 			return nil
 
 		case tcell.KeyRight, tcell.KeyTab:
-			c.confirmDeleteYes = false
-			c.renderConfirmDeletePrompt()
+			c.confirmYes = false
+			c.renderConfirmPrompt()
 			return nil
 
 		case tcell.KeyRune:
 			if event.Modifiers() == tcell.ModNone {
 				switch event.Rune() {
 				case 'h':
-					c.confirmDeleteYes = true
-					c.renderConfirmDeletePrompt()
+					c.confirmYes = true
+					c.renderConfirmPrompt()
 					return nil
 				case 'l':
-					c.confirmDeleteYes = false
-					c.renderConfirmDeletePrompt()
+					c.confirmYes = false
+					c.renderConfirmPrompt()
 					return nil
 				}
 			}
@@ -394,6 +541,77 @@ func (c *Controller) HandleInput(event *tcell.EventKey) *tcell.EventKey {
 			c.showRootMenu("")
 			return nil
 		}
+	case modeSnapshotList:
+		switch event.Key() {
+		case tcell.KeyRune:
+			if event.Modifiers() == tcell.ModNone {
+				switch event.Rune() {
+				case 'p':
+					c.showSnapshotName(true)
+					return nil
+				case 'x', 'X':
+					c.deleteSelectedSnapshot()
+					return nil
+				}
+			}
+		case tcell.KeyEnter:
+			c.showSelectedSnapshot()
+			return nil
+		case tcell.KeyCtrlC, tcell.KeyEsc:
+			c.showRootMenu("")
+			return nil
+		}
+	case modeSnapshotName:
+		switch event.Key() {
+		case tcell.KeyEnter:
+			c.pinSnapshot()
+			return nil
+		case tcell.KeyCtrlC, tcell.KeyEsc:
+			if c.pinFromList {
+				c.showSnapshots("")
+			} else {
+				c.Close()
+			}
+
+			return nil
+		}
+	case modeSaveOutput:
+		switch event.Key() {
+		case tcell.KeyTab:
+			c.completeSavePath()
+			return nil
+		case tcell.KeyEnter:
+			if c.saving == nil {
+				c.saveOutput(false)
+			}
+
+			return nil
+		case tcell.KeyCtrlC, tcell.KeyEsc:
+			if c.saving != nil {
+				c.cancelSave()
+				c.updateSaveOutputTitle("cancelled")
+				return nil
+			}
+
+			c.showRootMenu("")
+			return nil
+		case tcell.KeyRune:
+			if event.Modifiers() == tcell.ModAlt {
+				switch event.Rune() {
+				case 'c':
+					c.saveOptions.Compact = !c.saveOptions.Compact
+				case 'r':
+					c.saveOptions.Raw = !c.saveOptions.Raw
+				case 's':
+					c.saveOptions.Sort = !c.saveOptions.Sort
+				default:
+					return event
+				}
+
+				c.saveOptionsView.SetText(c.saveOptions.String())
+				return nil
+			}
+		}
 	case modeCheatSheet, modeKeybindings:
 		switch event.Key() {
 		case tcell.KeyCtrlC, tcell.KeyEsc:
@@ -431,14 +649,24 @@ func (c *Controller) activateRootMenu(index int) {
 		c.showKeybindings()
 	case 4:
 		c.showCheatSheet()
+	case 5:
+		c.showSnapshots("")
+	case 6:
+		c.showSaveOutput("")
 	}
 }
 
 func (c *Controller) showConfigure() {
 	c.mode = modeConfigure
 	c.subpages.SwitchToPage(configurePage)
-	c.refreshConfigure(c.configure.GetCurrentItem())
-	c.resize(configureSize.Width, configureSize.Height)
+	rows := c.refreshConfigure(c.configure.GetCurrentItem())
+
+	width := configureSize.Width
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	c.resize(width, max(configureSize.Height, len(rows)+1))
 	c.app.SetFocus(c.configure)
 }
 
@@ -483,6 +711,19 @@ func (c *Controller) toggleConfigure(index int) {
 	c.refreshConfigure(index)
 }
 
+func (c *Controller) adjustConfigure(index int, delta int) {
+	if c.callbacks.AdjustConfigureRow == nil {
+		return
+	}
+
+	if index < 0 || index >= len(configureRows) {
+		return
+	}
+
+	c.callbacks.AdjustConfigureRow(configureRows[index], delta)
+	c.refreshConfigure(index)
+}
+
 func (c *Controller) saveCurrentFilterToHistory() {
 	if c.callbacks.SaveCurrentFilterToHistory == nil {
 		c.showRootMenu("save action unavailable")
@@ -592,9 +833,10 @@ func (c *Controller) promptDeleteSelectedHistoryEntry() {
 
 	c.pendingDeleteIndex = index
 	c.pendingDeleteEntry = entry
-	c.confirmDeleteYes = true
+	c.confirmYes = true
 	c.mode = modeHistoryConfirmDelete
 	c.subpages.SwitchToPage(confirmDeletePage)
+	c.confirmDeleteView.SetTitle("Confirm delete")
 
 	width := max(
 		tview.TaggedStringWidth(tview.Escape(strings.ReplaceAll(entry, "\n", " "))),
@@ -602,7 +844,7 @@ func (c *Controller) promptDeleteSelectedHistoryEntry() {
 	)
 
 	c.resize(width, confirmDeleteHeight)
-	c.renderConfirmDeletePrompt()
+	c.renderConfirmPrompt()
 	c.app.SetFocus(c.confirmDeleteView)
 }
 
@@ -610,7 +852,7 @@ func (c *Controller) confirmDeleteSelection(yes bool) {
 	index := c.pendingDeleteIndex
 	c.pendingDeleteIndex = -1
 	c.pendingDeleteEntry = ""
-	c.confirmDeleteYes = true
+	c.confirmYes = true
 
 	c.mode = modeHistoryList
 	c.subpages.SwitchToPage(historyPage)
@@ -635,18 +877,33 @@ func (c *Controller) confirmDeleteSelection(yes bool) {
 	c.updateHistoryTitle("deleted")
 }
 
-func (c *Controller) renderConfirmDeletePrompt() {
-	entry := tview.Escape(strings.ReplaceAll(c.pendingDeleteEntry, "\n", " "))
+// confirmSelection answers the confirmation prompt which is open.
+func (c *Controller) confirmSelection(yes bool) {
+	if c.mode == modeSaveConfirmOverwrite {
+		c.confirmOverwrite(yes)
+		return
+	}
+
+	c.confirmDeleteSelection(yes)
+}
+
+func (c *Controller) renderConfirmPrompt() {
+	prompt, entry := confirmDeletePromptText, c.pendingDeleteEntry
+	if c.mode == modeSaveConfirmOverwrite {
+		prompt, entry = confirmOverwritePromptText, c.pendingSavePath
+	}
+
+	entry = tview.Escape(strings.ReplaceAll(entry, "\n", " "))
 
 	yes := " Yes "
 	no := " No "
-	if c.confirmDeleteYes {
+	if c.confirmYes {
 		yes = "[::r] Yes [-:-:-]"
 	} else {
 		no = "[::r] No [-:-:-]"
 	}
 
-	c.confirmDeleteView.SetText(fmt.Sprintf("%s\n\n[yellow]%s[-]\n\n%s %s", confirmDeletePromptText, entry, yes, no))
+	c.confirmDeleteView.SetText(fmt.Sprintf("%s\n\n[yellow]%s[-]\n\n%s %s", prompt, entry, yes, no))
 }
 
 func (c *Controller) applySelectedHistoryEntry() {
@@ -671,6 +928,211 @@ func (c *Controller) showCheatSheet() {
 	c.resize(cheatSheetSize.Width, cheatSheetSize.Height)
 }
 
+func (c *Controller) showSnapshots(status string) {
+	c.mode = modeSnapshotList
+	c.subpages.SwitchToPage(snapshotsPage)
+	c.resize(smallWidth, historyHeight)
+	if c.callbacks.LoadSnapshots != nil {
+		c.snapshotEntries = c.callbacks.LoadSnapshots()
+	} else {
+		c.snapshotEntries = nil
+	}
+
+	c.refreshSnapshots(c.snapshots.GetCurrentItem(), status)
+	c.app.SetFocus(c.snapshots)
+}
+
+func (c *Controller) refreshSnapshots(current int, status string) {
+	c.snapshots.Clear()
+	for _, entry := range c.snapshotEntries {
+		c.snapshots.AddItem(formatSnapshotEntry(entry), "", 0, nil)
+	}
+
+	if len(c.snapshotEntries) > 0 {
+		c.snapshots.SetCurrentItem(min(max(current, 0), len(c.snapshotEntries)-1))
+	}
+
+	title := fmt.Sprintf("Snapshots (%d)", len(c.snapshotEntries))
+	if strings.TrimSpace(status) != "" {
+		title = fmt.Sprintf("%s - %s", title, status)
+	}
+
+	c.snapshots.SetTitle(title)
+}
+
+// formatSnapshotEntry returns the row of a snapshot in the list of snapshots:
+// its name followed by its filter on a single line.
+func formatSnapshotEntry(entry SnapshotEntry) string {
+	return entry.Name + "   " + strings.Join(strings.Fields(entry.Filter), " ")
+}
+
+func (c *Controller) showSnapshotName(fromList bool) {
+	c.mode = modeSnapshotName
+	c.pinFromList = fromList
+	c.subpages.SwitchToPage(snapshotNamePage)
+	c.resize(smallWidth, snapshotNameHeight)
+	c.snapshotNameInput.SetText("")
+	c.app.SetFocus(c.snapshotNameInput)
+}
+
+func (c *Controller) pinSnapshot() {
+	if c.callbacks.PinSnapshot == nil {
+		c.showSnapshots("pin action unavailable")
+		return
+	}
+
+	if err := c.callbacks.PinSnapshot(strings.TrimSpace(c.snapshotNameInput.GetText())); err != nil {
+		c.showSnapshots(err.Error())
+		return
+	}
+
+	c.Close()
+}
+
+func (c *Controller) showSelectedSnapshot() {
+	selected := c.snapshots.GetCurrentItem()
+	if c.callbacks.ShowSnapshot == nil || selected < 0 || selected >= len(c.snapshotEntries) {
+		return
+	}
+
+	c.callbacks.ShowSnapshot(selected)
+	c.Close()
+}
+
+func (c *Controller) deleteSelectedSnapshot() {
+	selected := c.snapshots.GetCurrentItem()
+	if c.callbacks.DeleteSnapshotAt == nil || selected < 0 || selected >= len(c.snapshotEntries) {
+		return
+	}
+
+	if err := c.callbacks.DeleteSnapshotAt(selected); err != nil {
+		c.refreshSnapshots(selected, err.Error())
+		return
+	}
+
+	if c.callbacks.LoadSnapshots != nil {
+		c.snapshotEntries = c.callbacks.LoadSnapshots()
+	}
+
+	c.refreshSnapshots(selected, "deleted")
+}
+
+func (c *Controller) showSaveOutput(status string) {
+	c.mode = modeSaveOutput
+	c.subpages.SwitchToPage(saveOutputPage)
+	c.resize(max(smallWidth, tview.TaggedStringWidth(c.saveOptions.String())), saveOutputHeight)
+	c.saveOptionsView.SetText(c.saveOptions.String())
+	c.updateSaveOutputTitle(status)
+	c.app.SetFocus(c.savePathInput)
+}
+
+func (c *Controller) updateSaveOutputTitle(status string) {
+	title := "Save output as"
+	if strings.TrimSpace(status) != "" {
+		title = fmt.Sprintf("%s - %s", title, status)
+	}
+
+	c.saveOutputBox.SetTitle(title)
+}
+
+// completeSavePath completes the path being entered and, if several files
+// match it, lists them in the title.
+func (c *Controller) completeSavePath() {
+	path, matches := completePath(c.savePathInput.GetText())
+	c.savePathInput.SetText(path)
+
+	switch len(matches) {
+	case 0:
+		c.updateSaveOutputTitle("no matches")
+	case 1:
+		c.updateSaveOutputTitle("")
+	default:
+		c.updateSaveOutputTitle(strings.Join(matches, " "))
+	}
+}
+
+// saveOutput saves the output to the path being entered, after asking for
+// confirmation if a file would be overwritten.
+func (c *Controller) saveOutput(overwrite bool) {
+	path := strings.TrimSpace(c.savePathInput.GetText())
+	if path == "" {
+		c.updateSaveOutputTitle("path is empty")
+		return
+	}
+
+	if c.callbacks.SaveOutput == nil {
+		c.updateSaveOutputTitle("save action unavailable")
+		return
+	}
+
+	expanded := expandPath(path)
+	if info, err := os.Stat(expanded); err == nil {
+		if info.IsDir() {
+			c.updateSaveOutputTitle("path is a directory")
+			return
+		}
+
+		if !overwrite {
+			c.promptOverwrite(path)
+			return
+		}
+	}
+
+	save := &pendingSave{}
+	c.saving = save
+	save.cancel = c.callbacks.SaveOutput(expanded, c.saveOptions, func(err error) {
+		if c.saving != save {
+			return
+		}
+
+		c.saving = nil
+		if err != nil {
+			c.showSaveOutput(err.Error())
+			return
+		}
+
+		c.showRootMenu("saved " + path)
+	})
+
+	if c.saving == save {
+		c.updateSaveOutputTitle("saving…")
+	}
+}
+
+// cancelSave stops saving the output, if it is being saved.
+func (c *Controller) cancelSave() {
+	if c.saving != nil {
+		c.saving.cancel()
+		c.saving = nil
+	}
+}
+
+func (c *Controller) promptOverwrite(path string) {
+	c.pendingSavePath = path
+	c.confirmYes = false
+	c.mode = modeSaveConfirmOverwrite
+	c.subpages.SwitchToPage(confirmDeletePage)
+	c.confirmDeleteView.SetTitle("Confirm overwrite")
+
+	width := max(
+		tview.TaggedStringWidth(tview.Escape(path)),
+		tview.TaggedStringWidth(confirmOverwritePromptText),
+	)
+
+	c.resize(width, confirmDeleteHeight)
+	c.renderConfirmPrompt()
+	c.app.SetFocus(c.confirmDeleteView)
+}
+
+func (c *Controller) confirmOverwrite(yes bool) {
+	c.pendingSavePath = ""
+	c.confirmYes = true
+	c.showSaveOutput("")
+	if yes {
+		c.saveOutput(true)
+	}
+}
+
 func (c *Controller) showKeybindings() {
 	c.mode = modeKeybindings
 	c.subpages.SwitchToPage(keybindingsPage)
@@ -1,11 +1,15 @@
 package overlay
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"codeberg.org/gpanders/ijq/internal/options"
 )
@@ -232,3 +236,145 @@ func TestHandleInputCheatSheetAndKeybindingsReturnToRoot(t *testing.T) {
 	assert.Nil(t, event)
 	assert.Equal(t, modeRoot, controller.mode)
 }
+
+func TestHandleInputSnapshotsShowPinAndDelete(t *testing.T) {
+	entries := []SnapshotEntry{{Name: "one", Filter: ".a"}, {Name: "two", Filter: ".b\n| .c"}}
+	shownIndex := -1
+	pinnedName := ""
+
+	controller := newOpenController(t, Callbacks{
+		LoadSnapshots: func() []SnapshotEntry {
+			return append([]SnapshotEntry(nil), entries...)
+		},
+		ShowSnapshot: func(index int) {
+			shownIndex = index
+		},
+		DeleteSnapshotAt: func(index int) error {
+			entries = append(entries[:index], entries[index+1:]...)
+			return nil
+		},
+		PinSnapshot: func(name string) error {
+			pinnedName = name
+			return nil
+		},
+	})
+
+	controller.rootMenu.SetCurrentItem(5)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeSnapshotList, controller.mode)
+	assert.Equal(t, "Snapshots (2)", controller.snapshots.GetTitle())
+
+	main, _ := controller.snapshots.GetItemText(1)
+	assert.Equal(t, "two   .b | .c", main)
+
+	event := controller.HandleInput(runeEvent('x'))
+	assert.Nil(t, event)
+	assert.Equal(t, []SnapshotEntry{{Name: "two", Filter: ".b\n| .c"}}, controller.snapshotEntries)
+	assert.Equal(t, "Snapshots (1) - deleted", controller.snapshots.GetTitle())
+
+	// Cancelling the name prompt returns to the list
+	controller.HandleInput(runeEvent('p'))
+	assert.Equal(t, modeSnapshotName, controller.mode)
+	assert.True(t, controller.IsTextInput())
+
+	controller.HandleInput(keyEvent(tcell.KeyEsc))
+	assert.Equal(t, modeSnapshotList, controller.mode)
+
+	event = controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Nil(t, event)
+	assert.Equal(t, 0, shownIndex)
+	assert.False(t, controller.IsOpen())
+
+	controller.OpenPinPrompt()
+	assert.Equal(t, modeSnapshotName, controller.mode)
+	controller.snapshotNameInput.SetText("  three ")
+	event = controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Nil(t, event)
+	assert.Equal(t, "three", pinnedName)
+	assert.False(t, controller.IsOpen())
+
+	// Cancelling the prompt when it was opened directly closes the overlay
+	controller.OpenPinPrompt()
+	controller.HandleInput(keyEvent(tcell.KeyEsc))
+	assert.False(t, controller.IsOpen())
+	assert.False(t, controller.IsTextInput())
+}
+
+func TestHandleInputSaveOutputConfirmsOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+
+	var saved []SaveOptions
+	controller := newOpenController(t, Callbacks{
+		SaveOutput: func(path string, opts SaveOptions, done func(error)) func() {
+			saved = append(saved, opts)
+			done(nil)
+			return func() {}
+		},
+	})
+
+	controller.rootMenu.SetCurrentItem(6)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeSaveOutput, controller.mode)
+	assert.True(t, controller.IsTextInput())
+
+	event := controller.HandleInput(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModAlt))
+	assert.Nil(t, event)
+	assert.Equal(t, SaveOptions{Raw: true}, controller.saveOptions)
+
+	// Overwriting a file is declined by default
+	controller.savePathInput.SetText(path)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeSaveConfirmOverwrite, controller.mode)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeSaveOutput, controller.mode)
+	assert.Empty(t, saved)
+
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	controller.HandleInput(runeEvent('h'))
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeRoot, controller.mode)
+	assert.Equal(t, []SaveOptions{{Raw: true}}, saved)
+	assert.Contains(t, controller.rootMenu.GetTitle(), "saved")
+
+	// Files which do not exist yet are saved without asking
+	controller.rootMenu.SetCurrentItem(6)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	controller.savePathInput.SetText(filepath.Join(filepath.Dir(path), "new.json"))
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Equal(t, modeRoot, controller.mode)
+	assert.Len(t, saved, 2)
+}
+
+func TestHandleInputSaveOutputCancels(t *testing.T) {
+	var done func(error)
+	cancelled := false
+	controller := newOpenController(t, Callbacks{
+		SaveOutput: func(path string, opts SaveOptions, d func(error)) func() {
+			done = d
+			return func() { cancelled = true }
+		},
+	})
+
+	controller.rootMenu.SetCurrentItem(6)
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	controller.savePathInput.SetText(filepath.Join(t.TempDir(), "out.json"))
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	assert.Contains(t, controller.saveOutputBox.GetTitle(), "saving")
+
+	// Cancelling the save keeps the prompt open, and its result is
+	// ignored
+	controller.HandleInput(keyEvent(tcell.KeyEsc))
+	assert.True(t, cancelled)
+	assert.Equal(t, modeSaveOutput, controller.mode)
+	assert.Contains(t, controller.saveOutputBox.GetTitle(), "cancelled")
+
+	done(nil)
+	assert.Equal(t, modeSaveOutput, controller.mode)
+
+	// Errors are shown in the prompt
+	controller.HandleInput(keyEvent(tcell.KeyEnter))
+	done(errors.New("boom"))
+	assert.Equal(t, modeSaveOutput, controller.mode)
+	assert.Contains(t, controller.saveOutputBox.GetTitle(), "boom")
+}
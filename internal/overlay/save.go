@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package overlay
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// SaveOptions are the options the output is written to a file with. They are
+// independent of the options of the output pane.
+type SaveOptions struct {
+	Compact bool
+	Raw     bool
+	Sort    bool
+}
+
+// String returns the options as shown below the path of the file.
+func (o SaveOptions) String() string {
+	checkbox := func(checked bool, name string) string {
+		if checked {
+			return "● " + name
+		}
+
+		return "○ " + name
+	}
+
+	return fmt.Sprintf("%s   %s   %s",
+		checkbox(o.Compact, "compact (Alt-c)"),
+		checkbox(o.Raw, "raw (Alt-r)"),
+		checkbox(o.Sort, "sort keys (Alt-s)"),
+	)
+}
+
+// expandPath replaces a leading ~ in path with the home directory.
+func expandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
+
+// completePath completes the last element of path to the longest prefix
+// shared by the names in its directory which start with it. A directory
+// which is the only match is completed with a trailing slash. Hidden files
+// are only completed when the element starts with a dot. The names which
+// match are returned with the completed path.
+func completePath(path string) (string, []string) {
+	dir, base := filepath.Split(path)
+
+	entries, err := os.ReadDir(expandPath(cmp.Or(dir, ".")))
+	if err != nil {
+		return path, nil
+	}
+
+	var matches []string
+	isDir := false
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+
+		matches = append(matches, name)
+		isDir = entry.IsDir()
+	}
+
+	if len(matches) == 0 {
+		return path, nil
+	}
+
+	prefix := matches[0]
+	for _, name := range matches[1:] {
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	// Names may share only part of the encoding of a character
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+
+	if len(matches) == 1 && isDir {
+		prefix += string(filepath.Separator)
+	}
+
+	return dir + prefix, matches
+}
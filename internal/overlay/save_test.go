@@ -0,0 +1,73 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletePath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"data.json", "data.yaml", "notes.txt", ".hidden"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "out"), 0o755))
+
+	path, matches := completePath(filepath.Join(dir, "d"))
+	assert.Equal(t, filepath.Join(dir, "data."), path)
+	assert.Equal(t, []string{"data.json", "data.yaml"}, matches)
+
+	path, matches = completePath(filepath.Join(dir, "n"))
+	assert.Equal(t, filepath.Join(dir, "notes.txt"), path)
+	assert.Equal(t, []string{"notes.txt"}, matches)
+
+	// Directories are completed with a separator so that their files can
+	// be completed next
+	path, _ = completePath(filepath.Join(dir, "o"))
+	assert.Equal(t, filepath.Join(dir, "out")+string(filepath.Separator), path)
+
+	// Hidden files are only completed after a dot
+	_, matches = completePath(dir + string(filepath.Separator))
+	assert.Equal(t, []string{"data.json", "data.yaml", "notes.txt", "out"}, matches)
+
+	_, matches = completePath(dir + string(filepath.Separator) + ".")
+	assert.Equal(t, []string{".hidden"}, matches)
+
+	path, matches = completePath(filepath.Join(dir, "x"))
+	assert.Equal(t, filepath.Join(dir, "x"), path)
+	assert.Empty(t, matches)
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(home, "out.json"), expandPath("~/out.json"))
+	assert.Equal(t, home, expandPath("~"))
+	assert.Equal(t, "~user/out.json", expandPath("~user/out.json"))
+	assert.Equal(t, "out.json", expandPath("out.json"))
+}
+
+func TestSaveOptionsString(t *testing.T) {
+	assert.Equal(t, "○ compact (Alt-c)   ● raw (Alt-r)   ○ sort keys (Alt-s)", SaveOptions{Raw: true}.String())
+}
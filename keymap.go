@@ -221,6 +221,29 @@ type Keymap struct {
 	NextAutocomplete     KeyBindings `scfg:"next-autocomplete"`
 	PreviousAutocomplete KeyBindings `scfg:"previous-autocomplete"`
 	ToggleInputPane      KeyBindings `scfg:"toggle-input-pane"`
+	ToggleOriginalInput  KeyBindings `scfg:"toggle-original-input"`
+	NextTab              KeyBindings `scfg:"next-tab"`
+	PreviousTab          KeyBindings `scfg:"previous-tab"`
+	SearchForward        KeyBindings `scfg:"search-forward"`
+	SearchBackward       KeyBindings `scfg:"search-backward"`
+	NextMatch            KeyBindings `scfg:"next-match"`
+	PreviousMatch        KeyBindings `scfg:"previous-match"`
+	ToggleTreeView       KeyBindings `scfg:"toggle-tree-view"`
+	ExpandNode           KeyBindings `scfg:"expand-node"`
+	CollapseNode         KeyBindings `scfg:"collapse-node"`
+	InsertPath           KeyBindings `scfg:"insert-path"`
+	ToggleTableView      KeyBindings `scfg:"toggle-table-view"`
+	SortColumn           KeyBindings `scfg:"sort-column"`
+	HideColumn           KeyBindings `scfg:"hide-column"`
+	ShowColumns          KeyBindings `scfg:"show-columns"`
+	ToggleDiffView       KeyBindings `scfg:"toggle-diff-view"`
+	ToggleDiffBase       KeyBindings `scfg:"toggle-diff-base"`
+	PinSnapshot          KeyBindings `scfg:"pin-snapshot"`
+	ToggleSnapshot       KeyBindings `scfg:"toggle-snapshot"`
+	CopyFilter           KeyBindings `scfg:"copy-filter"`
+	CopyOutput           KeyBindings `scfg:"copy-output"`
+	CopyValue            KeyBindings `scfg:"copy-value"`
+	CopyPath             KeyBindings `scfg:"copy-path"`
 	SaveFilterHistory    KeyBindings `scfg:"save-filter-history"`
 	ToggleMenu           KeyBindings `scfg:"toggle-menu"`
 }
@@ -291,6 +314,29 @@ func DefaultKeymap() Keymap {
 		NextAutocomplete:     KeyBindings{{key: tcell.KeyTab}},
 		PreviousAutocomplete: KeyBindings{{key: tcell.KeyBacktab}},
 		ToggleInputPane:      KeyBindings{{key: tcell.KeyCtrlO}},
+		ToggleOriginalInput:  KeyBindings{{key: tcell.KeyCtrlT}},
+		NextTab:              KeyBindings{{key: tcell.KeyRune, rune: ']'}},
+		PreviousTab:          KeyBindings{{key: tcell.KeyRune, rune: '['}},
+		SearchForward:        KeyBindings{{key: tcell.KeyRune, rune: '/'}},
+		SearchBackward:       KeyBindings{{key: tcell.KeyRune, rune: '?'}},
+		NextMatch:            KeyBindings{{key: tcell.KeyRune, rune: 'n'}},
+		PreviousMatch:        KeyBindings{{key: tcell.KeyRune, rune: 'N'}},
+		ToggleTreeView:       KeyBindings{{key: tcell.KeyRune, rune: 't'}},
+		ExpandNode:           KeyBindings{{key: tcell.KeyRune, rune: 'l'}, {key: tcell.KeyRight}},
+		CollapseNode:         KeyBindings{{key: tcell.KeyRune, rune: 'h'}, {key: tcell.KeyLeft}},
+		InsertPath:           KeyBindings{{key: tcell.KeyRune, rune: 'p'}},
+		ToggleTableView:      KeyBindings{{key: tcell.KeyRune, rune: 'T'}},
+		SortColumn:           KeyBindings{{key: tcell.KeyRune, rune: 's'}},
+		HideColumn:           KeyBindings{{key: tcell.KeyRune, rune: 'x'}},
+		ShowColumns:          KeyBindings{{key: tcell.KeyRune, rune: 'X'}},
+		ToggleDiffView:       KeyBindings{{key: tcell.KeyRune, rune: 'D'}},
+		ToggleDiffBase:       KeyBindings{{key: tcell.KeyRune, rune: 'B'}},
+		PinSnapshot:          KeyBindings{{key: tcell.KeyRune, rune: 'P'}},
+		ToggleSnapshot:       KeyBindings{{key: tcell.KeyRune, rune: 'S'}},
+		CopyFilter:           KeyBindings{{key: tcell.KeyRune, rune: 'c', mods: tcell.ModAlt}},
+		CopyOutput:           KeyBindings{{key: tcell.KeyRune, rune: 'y', mods: tcell.ModAlt}},
+		CopyValue:            KeyBindings{{key: tcell.KeyRune, rune: 'y'}},
+		CopyPath:             KeyBindings{{key: tcell.KeyRune, rune: 'Y'}},
 		SaveFilterHistory:    KeyBindings{{key: tcell.KeyCtrlS}},
 		ToggleMenu: KeyBindings{
 			{key: tcell.KeyCtrlUnderscore},
@@ -14,6 +14,29 @@ func TestDefaultKeymap(t *testing.T) {
 
 	assert.True(t, keymap.SubmitFilter.Matches(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone)))
 	assert.True(t, keymap.ToggleInputPane.Matches(tcell.NewEventKey(tcell.KeyCtrlO, ' ', tcell.ModNone)))
+	assert.True(t, keymap.ToggleOriginalInput.Matches(tcell.NewEventKey(tcell.KeyCtrlT, ' ', tcell.ModNone)))
+	assert.True(t, keymap.NextTab.Matches(tcell.NewEventKey(tcell.KeyRune, ']', tcell.ModNone)))
+	assert.True(t, keymap.PreviousTab.Matches(tcell.NewEventKey(tcell.KeyRune, '[', tcell.ModNone)))
+	assert.True(t, keymap.SearchForward.Matches(tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone)))
+	assert.True(t, keymap.SearchBackward.Matches(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModNone)))
+	assert.True(t, keymap.NextMatch.Matches(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone)))
+	assert.True(t, keymap.PreviousMatch.Matches(tcell.NewEventKey(tcell.KeyRune, 'N', tcell.ModNone)))
+	assert.True(t, keymap.ToggleTreeView.Matches(tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone)))
+	assert.True(t, keymap.ExpandNode.Matches(tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone)))
+	assert.True(t, keymap.CollapseNode.Matches(tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone)))
+	assert.True(t, keymap.InsertPath.Matches(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone)))
+	assert.True(t, keymap.ToggleTableView.Matches(tcell.NewEventKey(tcell.KeyRune, 'T', tcell.ModNone)))
+	assert.True(t, keymap.SortColumn.Matches(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone)))
+	assert.True(t, keymap.HideColumn.Matches(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)))
+	assert.True(t, keymap.ShowColumns.Matches(tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone)))
+	assert.True(t, keymap.ToggleDiffView.Matches(tcell.NewEventKey(tcell.KeyRune, 'D', tcell.ModNone)))
+	assert.True(t, keymap.ToggleDiffBase.Matches(tcell.NewEventKey(tcell.KeyRune, 'B', tcell.ModNone)))
+	assert.True(t, keymap.PinSnapshot.Matches(tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone)))
+	assert.True(t, keymap.ToggleSnapshot.Matches(tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone)))
+	assert.True(t, keymap.CopyFilter.Matches(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModAlt)))
+	assert.True(t, keymap.CopyOutput.Matches(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModAlt)))
+	assert.True(t, keymap.CopyValue.Matches(tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone)))
+	assert.True(t, keymap.CopyPath.Matches(tcell.NewEventKey(tcell.KeyRune, 'Y', tcell.ModNone)))
 	assert.True(t, keymap.SaveFilterHistory.Matches(tcell.NewEventKey(tcell.KeyCtrlS, ' ', tcell.ModNone)))
 	assert.True(t, keymap.ToggleMenu.Matches(tcell.NewEventKey(tcell.KeyCtrlUnderscore, ' ', tcell.ModNone)))
 	assert.True(t, keymap.ToggleMenu.Matches(tcell.NewEventKey(tcell.KeyRune, '?', tcell.ModCtrl)))
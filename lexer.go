@@ -0,0 +1,283 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent    tokenKind = iota // select, if, mod::fn
+	tokenField                     // .foo
+	tokenDot                       // .
+	tokenRecurse                   // ..
+	tokenVariable                  // $foo, or $ alone
+	tokenFormat                    // @base64
+	tokenNumber                    // 1, 1.5e3, .5
+	tokenString                    // "foo"
+
+	// A string with interpolations is split into the text up to the
+	// first \( (tokenStringOpen), the text between interpolations
+	// (tokenStringMiddle), and the text after the last one
+	// (tokenStringClose). The tokens of each interpolation are in between.
+	tokenStringOpen
+	tokenStringMiddle
+	tokenStringClose
+
+	tokenPunct    // ( ) [ ] { } | , : ; ?
+	tokenOperator // + - * / % = == != < <= > >= |= += -= *= /= %= // //= ?//
+)
+
+// token is a token of a jq filter. start and end are byte offsets in the
+// filter.
+type token struct {
+	kind       tokenKind
+	text       string
+	start, end int
+
+	// unterminated is true for a string which is not closed before the end
+	// of the filter
+	unterminated bool
+}
+
+// operators are the operators of jq which are not single characters, longest
+// first so that they are matched before their prefixes.
+var operators = []string{"?//", "//=", "|=", "+=", "-=", "*=", "/=", "%=", "==", "!=", "<=", ">=", "//"}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || ('0' <= c && c <= '9')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// lexer splits a jq filter into tokens. It accepts incomplete filters, such
+// as the text before the cursor while a filter is typed, and never fails:
+// characters which are not part of the jq syntax are returned as operators.
+type lexer struct {
+	src string
+	pos int
+
+	// The number of unclosed parentheses in each enclosing string
+	// interpolation. A ) which closes an interpolation continues its
+	// string.
+	interpolations []int
+
+	// The start and end offsets of the comments skipped so far
+	comments [][2]int
+}
+
+// lex returns the tokens of filter. Comments are skipped.
+func lex(filter string) []token {
+	tokens, _ := lexComments(filter)
+	return tokens
+}
+
+// lexComments returns the tokens of filter and the start and end offsets of
+// its comments.
+func lexComments(filter string) ([]token, [][2]int) {
+	l := &lexer{src: filter}
+
+	var tokens []token
+	for {
+		t, ok := l.next()
+		if !ok {
+			return tokens, l.comments
+		}
+
+		tokens = append(tokens, t)
+	}
+}
+
+// blankComments returns filter with its comments replaced by spaces, so that
+// its parts can be joined on one line without changing their offsets.
+func blankComments(filter string) string {
+	_, comments := lexComments(filter)
+	if len(comments) == 0 {
+		return filter
+	}
+
+	b := []byte(filter)
+	for _, c := range comments {
+		for i := c[0]; i < c[1]; i++ {
+			b[i] = ' '
+		}
+	}
+
+	return string(b)
+}
+
+func (l *lexer) next() (token, bool) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.pos++
+		case c == '#':
+			start := l.pos
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+
+			l.comments = append(l.comments, [2]int{start, l.pos})
+		default:
+			return l.token(), true
+		}
+	}
+
+	return token{}, false
+}
+
+// token lexes the token at the current position, which is not whitespace.
+func (l *lexer) token() token {
+	start := l.pos
+	c := l.src[start]
+	emit := func(kind tokenKind, end int) token {
+		l.pos = end
+		return token{kind: kind, text: l.src[start:end], start: start, end: end}
+	}
+
+	switch {
+	case c == '"':
+		return l.string(start+1, tokenString, tokenStringOpen)
+	case c == ')' && len(l.interpolations) > 0 && l.interpolations[len(l.interpolations)-1] == 0:
+		// The end of an interpolation
+		l.interpolations = l.interpolations[:len(l.interpolations)-1]
+		return l.string(start+1, tokenStringClose, tokenStringMiddle)
+	case c == '.' && strings.HasPrefix(l.src[start:], ".."):
+		return emit(tokenRecurse, start+2)
+	case c == '.' && start+1 < len(l.src) && isDigit(l.src[start+1]):
+		return emit(tokenNumber, l.number(start))
+	case c == '.' && start+1 < len(l.src) && isIdentStart(l.src[start+1]):
+		return emit(tokenField, l.ident(start+1))
+	case c == '.':
+		return emit(tokenDot, start+1)
+	case c == '$':
+		return emit(tokenVariable, l.qualifiedIdent(start+1))
+	case c == '@':
+		return emit(tokenFormat, l.ident(start+1))
+	case isDigit(c):
+		return emit(tokenNumber, l.number(start))
+	case isIdentStart(c):
+		return emit(tokenIdent, l.qualifiedIdent(start))
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[start:], op) {
+			return emit(tokenOperator, start+len(op))
+		}
+	}
+
+	if n := len(l.interpolations); n > 0 {
+		switch c {
+		case '(':
+			l.interpolations[n-1]++
+		case ')':
+			l.interpolations[n-1]--
+		}
+	}
+
+	if strings.IndexByte("()[]{}|,:;?", c) >= 0 {
+		return emit(tokenPunct, start+1)
+	}
+
+	return emit(tokenOperator, start+1)
+}
+
+// ident returns the end of the identifier starting at pos.
+func (l *lexer) ident(pos int) int {
+	for pos < len(l.src) && isIdentChar(l.src[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+// qualifiedIdent returns the end of the identifier starting at pos, which may
+// be qualified with the names of modules, as in mod::fn.
+func (l *lexer) qualifiedIdent(pos int) int {
+	end := l.ident(pos)
+	for end > pos && strings.HasPrefix(l.src[end:], "::") {
+		end = l.ident(end + 2)
+	}
+
+	return end
+}
+
+// number returns the end of the number starting at pos.
+func (l *lexer) number(pos int) int {
+	for pos < len(l.src) && isDigit(l.src[pos]) {
+		pos++
+	}
+
+	if pos < len(l.src) && l.src[pos] == '.' {
+		pos++
+		for pos < len(l.src) && isDigit(l.src[pos]) {
+			pos++
+		}
+	}
+
+	// The exponent is part of the number even before its digits are typed
+	if pos < len(l.src) && (l.src[pos] == 'e' || l.src[pos] == 'E') {
+		pos++
+		if pos < len(l.src) && (l.src[pos] == '+' || l.src[pos] == '-') {
+			pos++
+		}
+
+		for pos < len(l.src) && isDigit(l.src[pos]) {
+			pos++
+		}
+	}
+
+	return pos
+}
+
+// string lexes the rest of a string from pos, up to the closing quote or the
+// start of an interpolation. The token starts at the current position.
+// closed is its kind if the string ends at a quote and open its kind if it
+// ends at an interpolation.
+func (l *lexer) string(pos int, closed, open tokenKind) token {
+	start := l.pos
+	for pos < len(l.src) {
+		switch l.src[pos] {
+		case '\\':
+			if strings.HasPrefix(l.src[pos:], `\(`) {
+				l.pos = pos + 2
+				l.interpolations = append(l.interpolations, 0)
+				return token{kind: open, text: l.src[start:l.pos], start: start, end: l.pos}
+			}
+
+			pos += 2
+		case '"':
+			l.pos = pos + 1
+			return token{kind: closed, text: l.src[start:l.pos], start: start, end: l.pos}
+		default:
+			pos++
+		}
+	}
+
+	l.pos = len(l.src)
+	return token{kind: closed, text: l.src[start:], start: start, end: len(l.src), unterminated: true}
+}
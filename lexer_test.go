@@ -0,0 +1,83 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLex(t *testing.T) {
+	type tok struct {
+		kind tokenKind
+		text string
+	}
+
+	kinds := func(filter string) []tok {
+		var toks []tok
+		for _, t := range lex(filter) {
+			toks = append(toks, tok{t.kind, t.text})
+		}
+		return toks
+	}
+
+	assert.Equal(t, []tok{
+		{tokenDot, "."},
+		{tokenPunct, "["},
+		{tokenPunct, "]"},
+		{tokenPunct, "|"},
+		{tokenIdent, "select"},
+		{tokenPunct, "("},
+		{tokenField, ".a"},
+		{tokenRecurse, ".."},
+		{tokenOperator, "=="},
+		{tokenNumber, "1.5e3"},
+		{tokenPunct, ")"},
+		{tokenOperator, "//"},
+		{tokenVariable, "$__loc__"},
+		{tokenPunct, "?"},
+		{tokenFormat, "@base64"},
+		{tokenIdent, "mod::fn"},
+		{tokenNumber, ".5"},
+	}, kinds(".[] | select(.a.. == 1.5e3) // $__loc__? @base64 mod::fn .5 # comment"))
+
+	// Interpolations split strings around their tokens
+	assert.Equal(t, []tok{
+		{tokenStringOpen, `"a \(`},
+		{tokenField, ".b"},
+		{tokenPunct, "("},
+		{tokenPunct, ")"},
+		{tokenStringMiddle, `) c \"\(`},
+		{tokenDot, "."},
+		{tokenStringClose, `)"`},
+		{tokenOperator, "|="},
+	}, kinds(`"a \(.b()) c \"\(.)" |=`))
+
+	tokens := lex(`."ab\"c`)
+	if assert.Len(t, tokens, 2) {
+		assert.True(t, tokens[1].unterminated)
+		assert.Equal(t, 1, tokens[1].start)
+		assert.Equal(t, 7, tokens[1].end)
+	}
+}
+
+func TestBlankComments(t *testing.T) {
+	assert.Equal(t, ".a      \n| .b", blankComments(".a # x |\n| .b"))
+	assert.Equal(t, `"# not a comment"`, blankComments(`"# not a comment"`))
+}
@@ -20,17 +20,21 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -40,20 +44,25 @@ import (
 	"codeberg.org/gpanders/ijq/internal/overlay"
 )
 
-// Special characters that, if present in a JSON key, need to be quoted in the
-// jq filter
-const specialChars string = ".-:$/"
-
-const alphabet string = "abcdefghijklmnopqrstuvwxyz"
-
 var Version string
 
 type Document struct {
-	input   string
-	filter  string
-	options options.Options
-	config  Config
-	ctx     context.Context
+	input     string
+	original  string
+	source    string
+	files     []string
+	tabs      []inputTab
+	selected  int
+	follow    io.Reader
+	filter    string
+	options   options.Options
+	config    Config
+	evaluator Evaluator
+	ctx       context.Context
+
+	// appendOutput makes WriteTo add the output to the end of a pane
+	// instead of replacing its text
+	appendOutput bool
 }
 
 func (d Document) WithFilter(filter string) Document {
@@ -62,6 +71,24 @@ func (d Document) WithFilter(filter string) Document {
 	return d
 }
 
+// convertInput sets the input of the document to input, converted into JSON
+// from the document's input format. The original text is kept so that it can
+// be shown in the input pane.
+func (d *Document) convertInput(input string) error {
+	converted, err := convertInput(input, d.options.InputFormat)
+	if err != nil {
+		return err
+	}
+
+	d.input = converted
+	d.original = ""
+	if converted != input {
+		d.original = input
+	}
+
+	return nil
+}
+
 func (d *Document) ReadFrom(r io.Reader) (n int64, err error) {
 	var buf bytes.Buffer
 	n, err = buf.ReadFrom(r)
@@ -71,65 +98,205 @@ func (d *Document) ReadFrom(r io.Reader) (n int64, err error) {
 
 func (d Document) WriteTo(w io.Writer) (n int64, err error) {
 	opts := d.options
-	if p, ok := w.(*pane); ok {
+	p, isPane := w.(*pane)
+	if isPane {
 		// Writer is a pane, so set options accordingly
-		opts.ForceColor = true
-		opts.Monochrome = false
-		opts.CompactOutput = false
-		opts.RawOutput = false
+		opts = paneOptions(opts)
 		w = tview.ANSIWriter(p)
 
 		// Mark the pane as dirty so the text view is cleared before
 		// new output is written.
-		p.dirty = true
-		defer func() {
-			if p.dirty && err == nil {
-				// If there was no error and the pane is still marked as dirty that
-				// means jq didn't emit any output, so we need to clear the pane
-				// manually
-				p.tv.Clear()
-			}
-		}()
+		if !d.appendOutput {
+			p.markDirty()
+			defer func() {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				if p.dirty && err == nil {
+					// If there was no error and the pane is still marked as dirty that
+					// means jq didn't emit any output, so we need to clear the pane
+					// manually
+					p.clear()
+				}
+			}()
+		}
 	}
 
-	args := append(opts.ToSlice(), d.filter)
-	cmd := exec.CommandContext(d.ctx, string(d.options.JQCommand), args...)
+	evaluator := d.evaluatorOrDefault()
+
+	if opts.Tabs && len(d.tabs) > 0 {
+		if opts.TabScope == "all" {
+			return 0, d.writeTabs(evaluator, opts, w, isPane)
+		}
+
+		d.ctx = withInputFile(d.ctx, d.tabs[d.selected].file())
+	}
 
-	var b bytes.Buffer
-	cmd.Stdin = strings.NewReader(d.input)
-	cmd.Stdout = w
-	cmd.Stderr = &b
+	return 0, d.write(evaluator, opts, w, isPane)
+}
+
+// AppendTo evaluates the filter and adds its output to the end of p, keeping
+// the text already in it. It returns the number of lines added.
+func (d Document) AppendTo(p *pane) (int, error) {
+	var buf bytes.Buffer
+	err := d.write(d.evaluatorOrDefault(), paneOptions(d.options), &buf, true)
+	if _, writeErr := tview.ANSIWriter(p).Write(buf.Bytes()); err == nil {
+		err = writeErr
+	}
+
+	return bytes.Count(buf.Bytes(), []byte("\n")), err
+}
 
-	if err := cmd.Run(); err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			exiterr.Stderr = b.Bytes()
+func (d Document) evaluatorOrDefault() Evaluator {
+	if d.evaluator == nil {
+		return execEvaluator{}
+	}
+
+	return d.evaluator
+}
+
+// paneOptions returns opts changed for output shown in a pane.
+func paneOptions(opts options.Options) options.Options {
+	opts.ForceColor = true
+	opts.Monochrome = false
+	opts.CompactOutput = false
+	opts.RawOutput = false
+	opts.Seq = false
+	opts.ExitStatus = false
+
+	// Stream events are shown one per line
+	if bool(opts.Stream) || bool(opts.StreamErrors) {
+		opts.CompactOutput = true
+	}
+
+	return opts
+}
+
+// write evaluates the filter against the input of the document and writes
+// its output to w.
+func (d Document) write(evaluator Evaluator, opts options.Options, w io.Writer, escape bool) error {
+	if opts.OutputFormat != "" && opts.OutputFormat != "json" {
+		return d.writeConverted(evaluator, opts, w, escape)
+	}
+
+	return evaluator.Evaluate(d.ctx, d.input, d.filter, opts, w)
+}
+
+// writeConverted evaluates the filter and writes its output to w converted
+// into the output format given in opts.
+func (d Document) writeConverted(evaluator Evaluator, opts options.Options, w io.Writer, escape bool) error {
+	// Conversion needs plain JSON values to work with
+	var buf bytes.Buffer
+	evalErr := evaluator.Evaluate(d.ctx, d.input, d.filter, jsonOptions(opts), &buf)
+	if evalErr != nil {
+		if _, ok := errorOutput(evalErr); !ok {
+			return evalErr
+		}
+	}
+
+	out, err := convertOutput(buf.String(), opts.OutputFormat)
+	if err != nil {
+		if evalErr != nil {
+			return evalErr
 		}
-		return 0, err
+
+		return &EvalError{
+			Stderr: fmt.Appendf(nil, "ijq: cannot convert output to %s: %s\n", opts.OutputFormat, err),
+			Code:   exitRuntime,
+		}
+	}
+
+	if escape {
+		out = tview.Escape(out)
+	}
+
+	if _, err := io.WriteString(w, out); err != nil {
+		return err
 	}
 
-	return 0, nil
+	return evalErr
+}
+
+// jsonOptions returns opts changed so that the filter writes its output as
+// plain JSON values, one per line.
+func jsonOptions(opts options.Options) options.Options {
+	opts.CompactOutput = true
+	opts.RawOutput = false
+	opts.JoinOutput = false
+	opts.ASCIIOutput = false
+	opts.Seq = false
+	opts.ForceColor = false
+	opts.Monochrome = true
+	return opts
 }
 
 type pane struct {
 	tv    *tview.TextView
 	dirty bool
+
+	mu sync.Mutex
+
+	// The active search, the line of each of its matches and the index
+	// of the selected match
+	search   *regexp.Regexp
+	backward bool
+	matches  []int
+	current  int
+
+	// When matches are highlighted, raw holds the text of the pane
+	// without the highlights
+	raw         string
+	highlighted bool
 }
 
 func (pane *pane) Write(p []byte) (n int, err error) {
+	pane.mu.Lock()
+	defer pane.mu.Unlock()
+
 	if pane.dirty {
-		pane.tv.Clear()
+		pane.clear()
 		pane.dirty = false
+	} else if pane.highlighted {
+		// Highlights are added again once all output has been
+		// written
+		pane.restore()
 	}
 
 	return pane.tv.Write(p)
 }
 
+// markDirty makes the next write to the pane replace its text.
+func (pane *pane) markDirty() {
+	pane.mu.Lock()
+	defer pane.mu.Unlock()
+	pane.dirty = true
+}
+
+// clear removes all text from the pane. pane.mu must be held.
+func (pane *pane) clear() {
+	pane.tv.Clear()
+	pane.raw = ""
+	pane.highlighted = false
+}
+
+// Text returns the text of the pane with its style tags, but without the
+// highlights of a search.
+func (pane *pane) Text() string {
+	pane.mu.Lock()
+	defer pane.mu.Unlock()
+
+	if pane.highlighted {
+		return pane.raw
+	}
+
+	return pane.tv.GetText(false)
+}
+
 func newFlagSet(name string, options *options.Options, output io.Writer) (*flag.FlagSet, *string, *bool) {
 	flagSet := flag.NewFlagSet(name, flag.ExitOnError)
 	flagSet.SetOutput(output)
 	flagSet.Usage = func() {
 		fmt.Fprintf(output, "ijq - interactive jq\n\n")
-		fmt.Fprintf(output, "Usage: ijq [-cnsrRMSV] [-f file] [filter] [files ...]\n\n")
+		fmt.Fprintf(output, "Usage: ijq [-cnsreRMSV] [-f file] [-arg name value] [filter] [files ...]\n\n")
 		fmt.Fprintf(output, "Options:\n")
 
 		flagSet.VisitAll(func(f *flag.Flag) {
@@ -158,7 +325,28 @@ func newFlagSet(name string, options *options.Options, output io.Writer) (*flag.
 	flagSet.Var(&options.ForceColor, options.ForceColor.Flag(), "colorize JSON output")
 	flagSet.Var(&options.Monochrome, options.Monochrome.Flag(), "disable colored output")
 	flagSet.Var(&options.SortKeys, options.SortKeys.Flag(), "sort keys of each object on output")
+	flagSet.Var(&options.Indent, options.Indent.Flag(), "use `n` spaces for indentation (0-7)")
+	flagSet.Var(&options.Tab, options.Tab.Flag(), "use tabs for indentation")
+	flagSet.Var(&options.Stream, options.Stream.Flag(), "parse input in streaming fashion")
+	flagSet.Var(&options.StreamErrors, options.StreamErrors.Flag(), "like -stream, but report parse errors as values")
+	flagSet.Var(&options.Seq, options.Seq.Flag(), "use application/json-seq for input and output")
+	flagSet.Var(&options.ExitStatus, options.ExitStatus.Flag(), "set the exit status from the last output of the submitted filter")
+	flagSet.Var(&options.ExitStatus, "exit-status", "alias for -e")
 	flagSet.Var(&options.LibraryPaths, options.LibraryPaths.Flag(), "search modules from the `dir`ectory")
+	flagSet.Var(&options.StringArgs, options.StringArgs.Flag(), "set $name to the string value (`name value`)")
+	flagSet.Var(&options.JSONArgs, options.JSONArgs.Flag(), "set $name to the JSON value (`name value`)")
+	flagSet.Var(&options.SlurpFiles, options.SlurpFiles.Flag(), "set $name to an array of the JSON values in file (`name file`)")
+	flagSet.Var(&options.RawFiles, options.RawFiles.Flag(), "set $name to the contents of file (`name file`)")
+	flagSet.Var(&options.PositionalArgs, options.PositionalArgs.Flag(), "treat remaining arguments as positional string arguments")
+	flagSet.Var(&options.PositionalJSONArgs, options.PositionalJSONArgs.Flag(), "treat remaining arguments as positional JSON arguments")
+	flagSet.Var(&options.Watch, options.Watch.Flag(), "reload input files and re-run the filter when they change")
+	flagSet.Var(&options.OutputFormat, options.OutputFormat.Flag(), "convert output to `format` (json, yaml, csv, tsv or table)")
+	flagSet.Var(&options.InputFormat, options.InputFormat.Flag(), "convert input from `format` (json, yaml, toml, csv, tsv or xml) to JSON")
+	flagSet.Var(&options.Tabs, options.Tabs.Flag(), "show each input file in its own tab")
+	flagSet.Var(&options.TabScope, options.TabScope.Flag(), "run the filter on the `selected` tab or on all tabs with -tabs")
+	flagSet.Var(&options.Follow, options.Follow.Flag(), "read newline delimited values from stdin as they arrive")
+	flagSet.Var(&options.FollowMode, options.FollowMode.Flag(), "run the filter on `all` retained values or only on new values with -follow")
+	flagSet.Var(&options.FollowLimit, options.FollowLimit.Flag(), "retain at most `n` values with -follow (default 10000)")
 
 	// Legacy options kept for backward compatibility.
 	flagSet.Var(&options.HideInputPane, options.HideInputPane.Flag(), "hide input (left) viewing pane")
@@ -173,7 +361,7 @@ func newFlagSet(name string, options *options.Options, output io.Writer) (*flag.
 
 func parseArgs(options *options.Options) (string, []string) {
 	flagSet, filterFile, version := newFlagSet("ijq", options, os.Stderr)
-	if err := flagSet.Parse(os.Args[1:]); err != nil {
+	if err := flagSet.Parse(joinNamedArgs(flagSet, os.Args[1:])); err != nil {
 		log.Fatalln(err)
 	}
 
@@ -187,6 +375,10 @@ func parseArgs(options *options.Options) (string, []string) {
 
 	stdinIsTty := term.IsTerminal(int(os.Stdin.Fd()))
 
+	// With --args or --jsonargs the arguments following the filter are
+	// passed to the filter instead of being read as input files
+	positional := bool(options.PositionalArgs) || bool(options.PositionalJSONArgs)
+
 	if *filterFile != "" {
 		contents, err := os.ReadFile(*filterFile)
 		if err != nil {
@@ -194,7 +386,7 @@ func parseArgs(options *options.Options) (string, []string) {
 		}
 
 		filter = string(contents)
-	} else if len(args) > 1 || (len(args) > 0 && (!stdinIsTty || bool(options.NullInput))) {
+	} else if len(args) > 1 || (len(args) > 0 && (!stdinIsTty || bool(options.NullInput) || positional)) {
 		filter = args[0]
 		args = args[1:]
 	} else if len(args) == 0 && stdinIsTty && !bool(options.NullInput) {
@@ -202,9 +394,76 @@ func parseArgs(options *options.Options) (string, []string) {
 		os.Exit(1)
 	}
 
+	if positional {
+		options.Positional = append(options.Positional, args...)
+		args = nil
+	}
+
 	return filter, args
 }
 
+// joinNamedArgs rewrites jq style "-arg name value" arguments into the
+// "-arg name=value" form understood by the flag package.
+func joinNamedArgs(flagSet *flag.FlagSet, args []string) []string {
+	joined := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			// The flag package stops parsing at the first
+			// non-flag argument
+			return append(joined, args[i:]...)
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			joined = append(joined, arg)
+			continue
+		}
+
+		switch name {
+		case "arg", "argjson", "slurpfile", "rawfile":
+			if i+2 < len(args) {
+				joined = append(joined, arg, args[i+1]+"="+args[i+2])
+				i += 2
+				continue
+			}
+		}
+
+		joined = append(joined, arg)
+
+		// Skip over the value of flags which take one so that it is not
+		// mistaken for a positional argument
+		if f := flagSet.Lookup(name); f != nil && i+1 < len(args) {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				joined = append(joined, args[i+1])
+				i++
+			}
+		}
+	}
+
+	return joined
+}
+
+// variableNames returns the names of the variables available to a filter,
+// without the leading $.
+func variableNames(opts options.Options) []string {
+	names := []string{"ENV", "ARGS"}
+	if opts.Engine != "gojq" {
+		// gojq does not define $__loc__
+		names = append(names, "__loc__")
+	}
+
+	for _, args := range [][]options.NamedArg{opts.StringArgs, opts.JSONArgs, opts.SlurpFiles, opts.RawFiles} {
+		for _, arg := range args {
+			if !slices.Contains(names, arg.Name) {
+				names = append(names, arg.Name)
+			}
+		}
+	}
+
+	return names
+}
+
 func scrollHalfPage(tv *tview.TextView, up bool) {
 	_, _, _, height := tv.GetInnerRect()
 	row, col := tv.GetScrollOffset()
@@ -215,6 +474,20 @@ func scrollHalfPage(tv *tview.TextView, up bool) {
 	}
 }
 
+// scrollTableHalfPage moves the selection of a table by half a page.
+func scrollTableHalfPage(table *tview.Table, up bool) {
+	_, _, _, height := table.GetInnerRect()
+	row, column := table.GetSelection()
+	if up {
+		row -= height / 2
+	} else {
+		row += height / 2
+	}
+
+	// The first row is the header, which cannot be selected
+	table.Select(min(max(row, 1), table.GetRowCount()-1), column)
+}
+
 func scrollHorizontally(tv *tview.TextView, end bool) {
 	if end {
 		text := tv.GetText(true)
@@ -244,21 +517,53 @@ func scrollHorizontally(tv *tview.TextView, end bool) {
 	}
 }
 
-func updateScrollIndicator(name string, lineCount int, tv *tview.TextView) {
+// updateScrollIndicator sets the title of tv to name followed by the scroll
+// position and, if it is not empty, the status of a search in the pane.
+func updateScrollIndicator(name string, lineCount int, tv *tview.TextView, search string) {
+	if search != "" {
+		search = " · " + search
+	}
+
 	row, _ := tv.GetScrollOffset()
+	_, _, _, height := tv.GetInnerRect()
+	tv.SetTitle(fmt.Sprintf("%s (%s)%s", name, scrollPosition(row, height, lineCount), search))
+}
+
+// updateTreeScrollIndicator sets the title of a tree view to name followed by
+// the scroll position.
+func updateTreeScrollIndicator(name string, tree *tview.TreeView) {
+	_, _, _, height := tree.GetInnerRect()
+	position := scrollPosition(tree.GetScrollOffset(), height, tree.GetRowCount())
+	tree.SetTitle(fmt.Sprintf("%s (%s)", name, position))
+}
+
+// updateTableScrollIndicator sets the title of a table to name followed by
+// the scroll position and the status of the table.
+func updateTableScrollIndicator(name string, table *objectTable) {
+	status := table.Status()
+	if status != "" {
+		status = " · " + status
+	}
+
+	// The header row is always shown
+	row, _ := table.view.GetOffset()
+	_, _, _, height := table.view.GetInnerRect()
+	position := scrollPosition(row, height-1, table.view.GetRowCount()-1)
+	table.view.SetTitle(fmt.Sprintf("%s (%s)%s", name, position, status))
+}
+
+// scrollPosition describes how far a view of height rows is scrolled when row
+// is the first of lineCount rows it shows.
+func scrollPosition(row, height, lineCount int) string {
 	if row <= 0 {
-		tv.SetTitle(fmt.Sprintf("%s (Top)", name))
-		return
+		return "Top"
 	}
 
-	_, _, _, height := tv.GetInnerRect()
 	if row+height >= lineCount {
-		tv.SetTitle(fmt.Sprintf("%s (Bot)", name))
-		return
+		return "Bot"
 	}
 
-	percent := row * 100 / lineCount
-	tv.SetTitle(fmt.Sprintf("%s (%d%%)", name, percent))
+	return fmt.Sprintf("%d%%", row*100/lineCount)
 }
 
 func buildMainHelpText(keymap Keymap) string {
@@ -275,7 +580,37 @@ func buildMainHelpText(keymap Keymap) string {
 	return fmt.Sprintf("[::d]%s[::-] [::b]menu[::-]   [::d]Ctrl-C[::-] [::b]quit[::-]   [::d]%s[::-] [::b]quit and write output[::-]", menuKey, submitKey)
 }
 
-func createApp(doc Document) *tview.Application {
+// buildPathHelpText returns the help line shown while a value is selected in
+// the input pane, which starts with the path of the value.
+func buildPathHelpText(keymap Keymap, path string) string {
+	help := buildMainHelpText(keymap)
+	insertKey := keymap.InsertPath.PrimaryString()
+	if insertKey == "" {
+		return fmt.Sprintf("[::b]%s[::-]   %s", tview.Escape(path), help)
+	}
+
+	return fmt.Sprintf("[::b]%s[::-]   [::d]%s[::-] [::b]insert path[::-]   %s", tview.Escape(path), insertKey, help)
+}
+
+// saveOutput writes the output of d to the file at path. The file is only
+// written once the filter has succeeded, so that a failing filter does not
+// overwrite it.
+func saveOutput(d Document, path string) error {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		if stderr, ok := errorOutput(err); ok {
+			return errors.New(strings.TrimSpace(stderr))
+		}
+
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// createApp creates the application for doc. When the application stops,
+// status holds the code ijq should exit with.
+func createApp(doc Document, status *int) *tview.Application {
 	app := tview.NewApplication()
 
 	// tview uses colors for a dark background by default, so reset some of
@@ -295,6 +630,17 @@ func createApp(doc Document) *tview.Application {
 	outputView.SetDynamicColors(true).SetWrap(false).SetBorder(true)
 	outputPane := pane{tv: outputView}
 
+	// Each pane shows either its text or a tree of its values, which can
+	// be switched between independently
+	inputTree := newJSONTree()
+	inputBox := tview.NewFlex().AddItem(inputView, 0, 1, true)
+
+	outputTree := newJSONTree()
+	outputTable := newObjectTable()
+	outputDiff := newDiffView()
+	snapshots := newSnapshotPane()
+	outputBox := tview.NewFlex().AddItem(outputView, 0, 1, true)
+
 	errorView := tview.NewTextView()
 	errorView.SetDynamicColors(false).SetTitle("Error").SetBorder(true)
 
@@ -303,6 +649,52 @@ func createApp(doc Document) *tview.Application {
 	helpView.SetTextAlign(tview.AlignCenter)
 	helpView.SetText(buildMainHelpText(doc.config.Keymap))
 
+	// The path of the value selected in the input pane, which is shown in
+	// the help line and can be inserted into the filter, and of the value
+	// selected in the output pane. Either can be copied.
+	var inputPath, outputPath []any
+	selectInputPath := func(path []any) {
+		inputPath = path
+		helpView.SetText(buildPathHelpText(doc.config.Keymap, jqPath(path)))
+	}
+
+	inputTree.view.SetChangedFunc(func(node *tview.TreeNode) {
+		if ref, ok := node.GetReference().(treeValue); ok {
+			selectInputPath(ref.path)
+		}
+	})
+
+	outputTree.view.SetChangedFunc(func(node *tview.TreeNode) {
+		if ref, ok := node.GetReference().(treeValue); ok {
+			outputPath = ref.path
+		}
+	})
+
+	// Select the path of the line which is clicked in a text pane
+	selectClickedPath := func(tv *tview.TextView, selectPath func([]any)) func(tview.MouseAction, *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		return func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+			x, y := event.Position()
+			if action != tview.MouseLeftClick || !tv.InInnerRect(x, y) {
+				return action, event
+			}
+
+			// Lines are not wrapped, so each row of the pane is a line
+			_, top, _, _ := tv.GetInnerRect()
+			row, _ := tv.GetScrollOffset()
+			paths := linePaths(tv.GetText(true))
+			if line := row + y - top; line < len(paths) && paths[line] != nil {
+				selectPath(paths[line])
+			}
+
+			return action, event
+		}
+	}
+
+	inputView.SetMouseCapture(selectClickedPath(inputView, selectInputPath))
+	outputView.SetMouseCapture(selectClickedPath(outputView, func(path []any) {
+		outputPath = path
+	}))
+
 	var filterHistory history
 	filterHistory.Init(string(doc.options.HistoryFile))
 	// If submit-filter includes Enter, we need SetDoneFunc to handle submission so
@@ -328,7 +720,13 @@ func createApp(doc Document) *tview.Application {
 		filterHistory.Add(doc.filter)
 
 		if _, err := doc.WriteTo(os.Stdout); err != nil {
-			log.Fatalln(err)
+			if stderr, ok := errorOutput(err); ok {
+				fmt.Fprint(os.Stderr, stderr)
+			} else {
+				log.Println(err)
+			}
+
+			*status = exitCode(err)
 		}
 	}
 
@@ -342,6 +740,11 @@ func createApp(doc Document) *tview.Application {
 	// Initialize pending to true so that the output pane will update with the initial filter
 	pending := true
 
+	// The values read with -follow in the new mode which have not been
+	// filtered yet, while nothing else about the document changed. Only
+	// their output is added to the output pane.
+	var appended string
+
 	// Create a cancellable context when writing to the output view. If the
 	// filter input changes, the context is cancelled and the process is
 	// killed. This must be set before filterInput is created because
@@ -349,119 +752,205 @@ func createApp(doc Document) *tview.Application {
 	// spawns a goroutine reading doc.
 	doc.ctx, cancel = context.WithCancel(context.Background())
 
-	filterMap := make(map[string][]string)
+	// The keys completed for recently typed filters. inputGeneration is
+	// increased each time the input changes so that keys of the old input
+	// are not completed.
+	keyCache := newKeyCache(keyCacheSize)
+	inputGeneration := 0
+
+	// Builtins are listed by the engine in the background, since running
+	// the jq binary would delay the start of the UI
+	var builtins builtinSet
+	go builtins.Load(context.Background(), doc.options)
+	completions := newCompletionList()
 	queueDocumentUpdate := func(update func(*Document)) {
 		mutex.Lock()
 		defer mutex.Unlock()
 
 		cancel()
 		update(&doc)
+		appended = ""
+		pending = true
+		cond.Signal()
+	}
+
+	// appendDocumentInput sets the input of the document to input, which
+	// ends with the values in added. Unlike other updates it does not
+	// cancel the running filter, so that only the added values need to be
+	// filtered next, unless a run on the whole input is pending already.
+	appendDocumentInput := func(input, added string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		doc.input = input
+		inputGeneration++
+		if !pending || appended != "" {
+			appended += added
+		}
+
 		pending = true
 		cond.Signal()
 	}
 
 	filterInput := tview.NewInputField()
+	filterCursor := newInputCursor(doc.filter)
+	setFilterText := func(text string) {
+		filterCursor.Set(text)
+		filterInput.SetText(text)
+	}
+
+	filterChanged := func(text string) {
+		filterCursor.Offset(text)
+		errorView.Clear()
+		filterInput.SetFieldTextColor(tcell.ColorDefault)
+
+		if text == doc.filter {
+			return
+		}
+
+		queueDocumentUpdate(func(next *Document) {
+			*next = next.WithFilter(text)
+		})
+	}
+
 	filterInput.
 		SetText(doc.filter).
 		SetFieldBackgroundColor(tcell.ColorDefault).
 		SetFieldTextColor(tcell.ColorDefault).
-		SetChangedFunc(func(text string) {
-			errorView.Clear()
-			filterInput.SetFieldTextColor(tcell.ColorDefault)
-
-			if text == doc.filter {
-				return
-			}
-
-			queueDocumentUpdate(func(next *Document) {
-				*next = next.WithFilter(text)
-			})
-		}).
+		SetChangedFunc(filterChanged).
 		SetDoneFunc(func(key tcell.Key) {
 			if key == tcell.KeyEnter && submitOnEnter {
 				submitFilter()
 			}
 		}).
 		SetAutocompleteFunc(func(text string) []string {
+			completions.Clear()
+
 			if text == "" {
-				return filterHistory.Entries()
+				return completions.Set(filterHistory.Entries(), nil, nil, "")
 			}
 
-			if pos := strings.LastIndexByte(text, '.'); pos != -1 {
-				prefix := text[0:pos]
-				trimmed := strings.TrimSpace(prefix)
+			cursor := filterCursor.Offset(text)
+			c := completionContextAt(text, cursor)
+			before, after := text[:c.start], text[cursor:]
+
+			mutex.Lock()
+			opts := doc.options
+			mutex.Unlock()
+
+			mode := doc.config.AutocompleteMatch
+			switch c.kind {
+			case completeVariable:
+				entries, hints, highlights := symbolEntries(before, after, variableCandidates(c, opts, mode))
+				return completions.Set(entries, hints, highlights, after)
+			case completeFunction:
+				entries, hints, highlights := symbolEntries(before, after, functionCandidates(c, opts, builtins.Complete(""), mode))
+				return completions.Set(entries, hints, highlights, after)
+			case completeModule:
+				entries, hints, highlights := symbolEntries(before, after, moduleCandidates(c, opts, mode))
+				return completions.Set(entries, hints, highlights, after)
+			case completeKey, completeQuotedKey:
+				if bool(opts.Stream) || bool(opts.StreamErrors) {
+					// Filters are applied to the events, which
+					// have no keys to complete
+					break
+				}
 
 				mutex.Lock()
-				candidates, ok := filterMap[trimmed]
+				lookup := doc.WithFilter(keysFilter(c.directives, c.input))
+				generation := inputGeneration
 				mutex.Unlock()
-				if ok {
-					cur := text[pos+1:]
-					var entries []string
-					for _, c := range candidates {
-						key := c[pos+1:]
-						if strings.HasPrefix(key, cur) {
-							entries = append(entries, c)
-						}
-					}
 
-					return entries
+				lookup.options.Seq = false
+				lookup.options.ExitStatus = false
+				lookup.options.OutputFormat = ""
+				lookup.options.TabScope = ""
+
+				// The key is taken from the same document as the
+				// lookup, so that keys looked up before an option
+				// is toggled are never completed after it
+				key := keyCacheKey{input: lookup.filter, options: keyOptions(lookup.options), generation: generation}
+				if keys, ok := keyCache.Get(key); ok {
+					entries, highlights := keyEntries(text, cursor, c, keys, mode)
+					return completions.Set(entries, nil, highlights, after)
 				}
 
-				go func() {
-					var filt string
-					if prefix != "" {
-						p, _ := strings.CutSuffix(trimmed, "|")
-						filt = p + "| keys"
-					} else {
-						filt = "keys"
-					}
-
-					mutex.Lock()
-					filtered := doc.WithFilter("[" + filt + "] | unique | first")
-					mutex.Unlock()
+				if !keyCache.Start(key) {
+					break
+				}
 
+				go func() {
+					// Keys are not looked up again for an input which
+					// fails, until it is evicted or the document
+					// changes
+					var keys map[string]int
 					var buf bytes.Buffer
-					_, err := filtered.WriteTo(&buf)
-					if err != nil {
-						return
-					}
-
-					var keys []string
-					if err := json.Unmarshal(buf.Bytes(), &keys); err != nil {
-						return
+					if _, err := lookup.WriteTo(&buf); err == nil {
+						keys, _ = decodeKeys(buf.Bytes())
 					}
 
-					entries := keys[:0]
-					for _, k := range keys {
-						if k == "" {
-							k = `""`
-						} else {
-							first := strings.ToLower(string(k[0]))
-							if strings.ContainsAny(k, specialChars) || !strings.Contains(alphabet, first) {
-								k = `"` + k + `"`
-							}
-						}
-
-						entries = append(entries, prefix+"."+k)
+					keyCache.Put(key, keys)
+					if len(keys) > 0 {
+						app.QueueUpdateDraw(func() {
+							filterInput.Autocomplete()
+						})
 					}
+				}()
+			}
 
-					mutex.Lock()
-					filterMap[trimmed] = entries
-					mutex.Unlock()
+			return nil
+		}).
+		SetAutocompletedFunc(func(_ string, index, source int) bool {
+			// The list shows the entries with style tags, so the
+			// text is set to the entry itself
+			entry, ok := completions.Entry(index)
+			if !ok {
+				return true
+			}
 
-					filterInput.Autocomplete()
+			setFilterText(entry)
+			if pos, ok := completions.Cursor(entry); ok {
+				// Setting the text moves the cursor to the end of
+				// it, but it belongs after the completed word. The
+				// handler runs in the event loop, so the update is
+				// queued from another goroutine.
+				go app.QueueUpdateDraw(func() {
+					filterCursor.Move(filterInput, pos)
+				})
+			}
 
-					app.Draw()
-				}()
+			if source == tview.AutocompletedNavigate {
+				return false
 			}
 
-			return nil
+			filterChanged(entry)
+			return true
 		}).
-		SetAutocompleteUseTags(false).
+		SetAutocompleteUseTags(true).
 		SetAutocompleteStyles(tcell.ColorBlack, tcell.StyleDefault.Background(tcell.ColorBlack), tcell.StyleDefault.Reverse(true)).
 		SetTitle("Filter").
 		SetBorder(true)
 
+	filterInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		filterCursor.Key(event, filterInput.GetText())
+		return event
+	})
+
+	filterInput.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftClick {
+			// Clicking an entry closes the autocomplete list
+			completions.Hide()
+		}
+
+		if action == tview.MouseLeftDown && filterInput.InRect(event.Position()) {
+			// Clicking in the input moves the cursor somewhere
+			// tview does not expose
+			filterCursor.Forget()
+		}
+
+		return action, event
+	})
+
 	saveCurrentFilterToHistory := func() (status string, expression string, err error) {
 		expression = strings.TrimSpace(filterInput.GetText())
 		if expression == "" {
@@ -497,20 +986,244 @@ func createApp(doc Document) *tview.Application {
 	inputLineCount.Store(10000)
 	outputLineCount.Store(10000)
 
-	// Process document with empty filter to populate input view
-	go func() {
+	// Whether the input pane shows the input text as it was before it was
+	// converted to JSON
+	var showOriginal atomic.Bool
+
+	// Show the values of d in each of views which is shown in place of the
+	// text of its pane. If the filter fails without output the previous
+	// values are kept, the same as the text of the pane.
+	renderValues := func(d Document, views ...valueView) {
+		var shown []valueView
+		for _, view := range views {
+			if view.Enabled() {
+				shown = append(shown, view)
+			}
+		}
+
+		if len(shown) == 0 {
+			return
+		}
+
+		values, err := d.values()
+		if err != nil && len(values) == 0 {
+			return
+		}
+
+		app.QueueUpdateDraw(func() {
+			for _, view := range shown {
+				view.SetValues(values)
+			}
+		})
+	}
+
+	// Show the differences between the output of d and the input, or the
+	// output of the filter the diff is based on, if the diff is shown
+	renderDiff := func(d Document) {
+		if !outputDiff.enabled.Load() {
+			return
+		}
+
+		base := d.WithFilter(".")
+		if filter := outputDiff.Base(); filter != "" {
+			base = d.WithFilter(filter)
+		}
+
+		base.ctx = d.ctx
+		if outputDiff.Base() == "" {
+			// Compare with the input as the input pane shows it
+			base.options.Stream = false
+			base.options.StreamErrors = false
+		}
+
+		before, err := base.values()
+		if err != nil && len(before) == 0 {
+			return
+		}
+
+		after, err := d.values()
+		if err != nil && len(after) == 0 {
+			return
+		}
+
+		// A diff of large arrays stops once the filter changes again
+		entries, err := diffValues(d.ctx, before, after)
+		if err != nil {
+			return
+		}
+
+		app.QueueUpdateDraw(func() {
+			outputDiff.SetEntries(entries, max(len(before), len(after)))
+		})
+	}
+
+	// Process the given input with an empty filter to populate input view
+	// The document shown in the input pane for input
+	inputDocument := func(input string) Document {
 		mutex.Lock()
 		initial := doc.WithFilter(".")
 		mutex.Unlock()
 
-		_, err := initial.WriteTo(&inputPane)
-		if err != nil {
+		initial.input = input
+		initial.tabs = nil
+
+		// The input pane shows the parsed document, not stream events
+		// or converted output
+		initial.options.Stream = false
+		initial.options.StreamErrors = false
+		initial.options.OutputFormat = ""
+		return initial
+	}
+
+	renderInput := func(input string) {
+		initial := inputDocument(input)
+
+		if showOriginal.Load() && initial.original != "" {
+			inputPane.markDirty()
+			fmt.Fprint(&inputPane, tview.Escape(initial.original))
+		} else if _, err := initial.WriteTo(&inputPane); err != nil {
 			log.Printf("Error while running jq on input: %s\n", err)
 			return
 		}
 
+		app.QueueUpdate(inputPane.refreshSearch)
+		renderValues(initial, inputTree)
+
 		inputLineCount.Store(int64(strings.Count(inputView.GetText(false), "\n")))
-	}()
+	}
+
+	// appendInput adds the values in added to the end of the input pane,
+	// which shows the values before them, the whole input being all
+	appendInput := func(all, added string) {
+		lines, err := inputDocument(added).AppendTo(&inputPane)
+		if err != nil {
+			log.Printf("Error while running jq on input: %s\n", err)
+		}
+
+		app.QueueUpdate(inputPane.refreshSearch)
+		renderValues(inputDocument(all), inputTree)
+
+		inputLineCount.Add(int64(lines))
+	}
+
+	// Values read with -follow are shown as they arrive
+	if doc.follow == nil {
+		go renderInput(doc.input)
+	}
+
+	// The time the input files were last reloaded, shown in the title of
+	// the input pane
+	var inputReloaded atomic.Pointer[time.Time]
+
+	if len(doc.files) > 0 {
+		go func() {
+			watcher := newFileWatcher(doc.files)
+			for range time.Tick(watchInterval) {
+				mutex.Lock()
+				watch := bool(doc.options.Watch)
+				mutex.Unlock()
+
+				if !watch || !watcher.Changed() {
+					continue
+				}
+
+				tabs, err := readTabs(doc.files)
+				if err != nil {
+					log.Printf("Error reloading input: %s\n", err)
+					continue
+				}
+
+				mutex.Lock()
+				reloaded := doc
+				mutex.Unlock()
+
+				reloaded.tabs = tabs
+				if err := reloaded.convertTabs(); err != nil {
+					log.Printf("Error reloading input: %s\n", err)
+					continue
+				}
+
+				var input string
+				queueDocumentUpdate(func(next *Document) {
+					next.tabs = reloaded.tabs
+					next.selectTab(next.selected)
+					input = next.input
+					inputGeneration++
+				})
+
+				now := time.Now()
+				inputReloaded.Store(&now)
+				renderInput(input)
+				app.Draw()
+			}
+		}()
+	}
+
+	if doc.follow != nil {
+		f := newFollower(options.EffectiveInt(&doc.options.FollowLimit))
+
+		// Keep both panes scrolled to the end as values arrive, until
+		// the user scrolls away
+		inputView.ScrollToEnd()
+		outputView.ScrollToEnd()
+
+		go func() {
+			if err := f.Run(doc.follow); err != nil {
+				log.Printf("Error reading input: %s\n", err)
+			}
+		}()
+
+		go func() {
+			for range f.ready {
+				time.Sleep(followInterval)
+
+				mutex.Lock()
+				follow := bool(doc.options.Follow)
+				mutex.Unlock()
+
+				// While following is paused values are still read, so
+				// that the writer is not blocked, and those which
+				// arrived meanwhile are taken once it is resumed
+				for !follow {
+					time.Sleep(followInterval)
+
+					mutex.Lock()
+					follow = bool(doc.options.Follow)
+					mutex.Unlock()
+				}
+
+				mutex.Lock()
+				mode := doc.options.FollowMode
+				limit := options.EffectiveInt(&doc.options.FollowLimit)
+				mutex.Unlock()
+
+				f.SetLimit(limit)
+				all, added, trimmed := f.Take()
+				if added == "" {
+					continue
+				}
+
+				if mode == "new" {
+					appendDocumentInput(all, added)
+				} else {
+					queueDocumentUpdate(func(next *Document) {
+						next.input = all
+						inputGeneration++
+					})
+				}
+
+				// The whole input is shown again only when older
+				// values were discarded
+				if trimmed || showOriginal.Load() {
+					renderInput(all)
+				} else {
+					appendInput(all, added)
+				}
+
+				app.Draw()
+			}
+		}()
+	}
 
 	go func() {
 		for {
@@ -527,17 +1240,28 @@ func createApp(doc Document) *tview.Application {
 			// queueDocumentUpdate always operate on a fully constructed
 			// context.
 			d.ctx, cancel = context.WithCancel(context.Background())
+
+			// The views show the output for the whole input
+			whole := d
+			if appended != "" {
+				d.input = appended
+				d.appendOutput = true
+				appended = ""
+			}
 			cond.L.Unlock()
 
 			_, err := d.WriteTo(&outputPane)
+			app.QueueUpdate(outputPane.refreshSearch)
+			if d.ctx.Err() == nil {
+				renderValues(whole, outputTree, outputTable)
+				renderDiff(whole)
+			}
 			if err != nil {
-				if exitErr, ok := err.(*exec.ExitError); ok {
-					if code := exitErr.ExitCode(); code != -1 {
-						app.QueueUpdate(func() {
-							filterInput.SetFieldTextColor(tcell.ColorMaroon)
-							fmt.Fprint(tview.ANSIWriter(errorView), string(exitErr.Stderr))
-						})
-					}
+				if stderr, ok := errorOutput(err); ok {
+					app.QueueUpdate(func() {
+						filterInput.SetFieldTextColor(tcell.ColorMaroon)
+						fmt.Fprint(tview.ANSIWriter(errorView), stderr)
+					})
 				}
 			} else {
 				outputLineCount.Store(int64(strings.Count(outputView.GetText(false), "\n")))
@@ -552,8 +1276,24 @@ func createApp(doc Document) *tview.Application {
 		inputPaneProportion = 0
 	}
 	viewFlex := tview.NewFlex().
-		AddItem(inputView, 0, inputPaneProportion, false).
-		AddItem(outputView, 0, 1, false)
+		AddItem(inputBox, 0, inputPaneProportion, false).
+		AddItem(outputBox, 0, 1, false).
+		AddItem(snapshots.view, 0, 0, false)
+
+	// Show or hide the snapshot pane after the snapshot it shows changed
+	updateSnapshotPane := func() {
+		if snapshots.visible {
+			viewFlex.ResizeItem(snapshots.view, 0, 1)
+			return
+		}
+
+		if snapshots.view.HasFocus() {
+			app.SetFocus(outputBox)
+		}
+
+		viewFlex.ResizeItem(snapshots.view, 0, 0)
+	}
+
 	grid := tview.NewGrid().
 		SetRows(0, 3, 4, 1).
 		SetColumns(0).
@@ -571,6 +1311,59 @@ func createApp(doc Document) *tview.Application {
 	pages := tview.NewPages().
 		AddPage("main", grid, true, true)
 
+	// The search prompt replaces the help line while a pane is searched
+	searchInput := tview.NewInputField()
+	searchInput.
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetFieldTextColor(tcell.ColorDefault)
+
+	var (
+		searchPane              *pane
+		searchBackward          bool
+		searchRow, searchColumn int
+	)
+
+	openSearch := func(p *pane, backward bool) {
+		searchPane, searchBackward = p, backward
+		searchRow, searchColumn = p.tv.GetScrollOffset()
+
+		label := "/"
+		if backward {
+			label = "?"
+		}
+
+		searchInput.SetLabel(label).SetText("")
+		grid.RemoveItem(helpView)
+		grid.AddItem(searchInput, 3, 0, 1, 1, 0, 0, false)
+		app.SetFocus(searchInput)
+	}
+
+	closeSearch := func() {
+		grid.RemoveItem(searchInput)
+		grid.AddItem(helpView, 3, 0, 1, 1, 0, 0, false)
+		app.SetFocus(searchPane.tv)
+	}
+
+	searchInput.
+		SetChangedFunc(func(text string) {
+			if searchPane == nil {
+				return
+			}
+
+			// Search again from where the search started, so that the
+			// pane returns there if nothing matches
+			searchPane.tv.ScrollTo(searchRow, searchColumn)
+			searchPane.Search(compileSearch(text), searchBackward, searchRow)
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEscape {
+				searchPane.Search(nil, false, 0)
+				searchPane.tv.ScrollTo(searchRow, searchColumn)
+			}
+
+			closeSearch()
+		})
+
 	historyNotice := tview.NewTextView()
 	historyNotice.SetBorder(true)
 	historyNotice.SetTitle("History")
@@ -606,6 +1399,50 @@ func createApp(doc Document) *tview.Application {
 		isHistoryNoticeOpen = false
 	}
 
+	// Show a message in the help line for a few seconds
+	var helpMessage, helpBeforeMessage string
+	showHelpMessage := func(message string) {
+		if text := helpView.GetText(false); text != helpMessage {
+			helpBeforeMessage = text
+		}
+
+		shown := fmt.Sprintf("[::b]%s[::-]   %s", tview.Escape(message), buildMainHelpText(doc.config.Keymap))
+		helpMessage = shown
+		helpView.SetText(shown)
+		time.AfterFunc(3*time.Second, func() {
+			app.QueueUpdateDraw(func() {
+				if helpView.GetText(false) == shown {
+					helpView.SetText(helpBeforeMessage)
+				}
+			})
+		})
+	}
+
+	// Text is copied to the clipboard with the configured command, or
+	// else with an OSC 52 escape sequence. The escape sequence is written
+	// to the terminal before the screen is next drawn, so that it is not
+	// mixed with the output of drawing.
+	var pendingCopy *clipboardText
+	clipboardCommand := doc.config.ClipboardCommand
+	copyText := func(what, text string) {
+		if len(clipboardCommand) == 0 {
+			pendingCopy = &clipboardText{what: what, text: text}
+			return
+		}
+
+		go func() {
+			err := runClipboardCommand(clipboardCommand, text)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showHelpMessage(fmt.Sprintf("Failed to copy %s: %s", what, err))
+					return
+				}
+
+				showHelpMessage(fmt.Sprintf("Copied %s to the clipboard", what))
+			})
+		}()
+	}
+
 	overlayPopup := overlay.NewController(app, pages, "overlay", overlay.Callbacks{
 		ConfigureRows: func() []string { return overlay.ConfigureRows(doc.options) },
 		ToggleConfigureRow: func(option options.Option) {
@@ -618,19 +1455,74 @@ func createApp(doc Document) *tview.Application {
 				hidden := doc.options.HideInputPane
 				mutex.Unlock()
 				if hidden {
-					if inputView.HasFocus() {
-						app.SetFocus(outputView)
+					if inputBox.HasFocus() {
+						app.SetFocus(outputBox)
 					}
-					viewFlex.ResizeItem(inputView, 0, 0)
+					viewFlex.ResizeItem(inputBox, 0, 0)
 				} else {
-					viewFlex.ResizeItem(inputView, 0, 1)
+					viewFlex.ResizeItem(inputBox, 0, 1)
+				}
+			case *options.Engine:
+				var opts options.Options
+				queueDocumentUpdate(func(next *Document) {
+					next.options.Toggle(option)
+					next.evaluator = newEvaluator(next.options)
+					opts = next.options
+				})
+
+				go builtins.Load(context.Background(), opts)
+			case *options.InputFormat:
+				// Convert the original text again using the new format
+				var convertErr error
+				queueDocumentUpdate(func(next *Document) {
+					source := cmp.Or(next.original, next.input)
+					next.options.Toggle(option)
+					if len(next.tabs) > 0 {
+						convertErr = next.convertTabs()
+					} else {
+						convertErr = next.convertInput(source)
+					}
+
+					inputGeneration++
+				})
+
+				if convertErr != nil {
+					errorView.Clear()
+					fmt.Fprintf(errorView, "ijq: %s", convertErr)
 				}
+
+				mutex.Lock()
+				input := doc.input
+				mutex.Unlock()
+
+				go func() {
+					renderInput(input)
+					app.Draw()
+				}()
+			case *options.Tabs:
+				var input string
+				queueDocumentUpdate(func(next *Document) {
+					next.options.Toggle(option)
+					next.selectTab(next.selected)
+					input = next.input
+					inputGeneration++
+				})
+
+				go func() {
+					renderInput(input)
+					app.Draw()
+				}()
 			default:
 				queueDocumentUpdate(func(next *Document) {
 					next.options.Toggle(option)
 				})
 			}
 		},
+		AdjustConfigureRow: func(option options.Option, delta int) {
+			queueDocumentUpdate(func(next *Document) {
+				next.options.Adjust(option, delta)
+			})
+		},
 		SaveCurrentFilterToHistory: func() (string, error) {
 			status, _, err := saveCurrentFilterToHistory()
 			if err != nil {
@@ -659,7 +1551,7 @@ func createApp(doc Document) *tview.Application {
 		ApplyHistoryEntry: func(expression string) {
 			errorView.Clear()
 			filterInput.SetFieldTextColor(tcell.ColorDefault)
-			filterInput.SetText(expression)
+			setFilterText(expression)
 		},
 		ActiveKeybindings: func() []overlay.KeybindingEntry {
 			entries := doc.config.Keymap.Entries()
@@ -674,10 +1566,87 @@ func createApp(doc Document) *tview.Application {
 
 			return rows
 		},
+		PinSnapshot: func(name string) error {
+			mutex.Lock()
+			filter := doc.filter
+			mutex.Unlock()
+
+			snapshots.Show(snapshots.Pin(name, filter, outputPane.Text()))
+			updateSnapshotPane()
+			return nil
+		},
+		LoadSnapshots: func() []overlay.SnapshotEntry {
+			return snapshots.Entries()
+		},
+		ShowSnapshot: func(index int) {
+			snapshots.Show(index)
+			updateSnapshotPane()
+		},
+		SaveOutput: func(path string, opts overlay.SaveOptions, done func(error)) func() {
+			mutex.Lock()
+			d := doc.WithFilter(doc.filter)
+			mutex.Unlock()
+
+			var cancel context.CancelFunc
+			d.ctx, cancel = context.WithCancel(d.ctx)
+			d.options.CompactOutput = options.CompactOutput(opts.Compact)
+			d.options.RawOutput = options.RawOutput(opts.Raw)
+			d.options.SortKeys = options.SortKeys(opts.Sort)
+			d.options.ForceColor = false
+			d.options.Monochrome = true
+
+			// Outputs which are false or null are saved like any
+			// other
+			d.options.ExitStatus = false
+
+			go func() {
+				err := saveOutput(d, path)
+				app.QueueUpdateDraw(func() {
+					done(err)
+				})
+			}()
+
+			return cancel
+		},
+		DeleteSnapshotAt: func(index int) error {
+			err := snapshots.Delete(index)
+			updateSnapshotPane()
+			return err
+		},
 	})
 
 	pages.AddPage("overlay", overlayPopup.Primitive(), true, false)
 
+	// Switch a pane between its text and another view of its values. The
+	// views are only kept up to date while they are shown, so render is
+	// called to fill the view when it is shown again.
+	toggleView := func(box *tview.Flex, text, view tview.Primitive, render func()) {
+		focused := box.HasFocus()
+		shown := box.GetItem(0) == view
+		box.Clear()
+		if shown {
+			box.AddItem(text, 0, 1, true)
+		} else {
+			box.AddItem(view, 0, 1, true)
+		}
+
+		inputTree.enabled.Store(inputBox.GetItem(0) == inputTree.view)
+		outputTree.enabled.Store(outputBox.GetItem(0) == outputTree.view)
+		outputTable.enabled.Store(outputBox.GetItem(0) == outputTable.view)
+		outputDiff.enabled.Store(outputBox.GetItem(0) == outputDiff.view)
+		if !shown {
+			render()
+		}
+
+		if focused {
+			app.SetFocus(box)
+		}
+	}
+
+	renderOutput := func() {
+		queueDocumentUpdate(func(*Document) {})
+	}
+
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		focused := app.GetFocus()
 		keymap := doc.config.Keymap
@@ -693,6 +1662,10 @@ func createApp(doc Document) *tview.Application {
 				return nil
 			}
 
+			if overlayPopup.IsTextInput() {
+				return overlayPopup.HandleInput(event)
+			}
+
 			if keymap.MoveDown.Matches(event) {
 				event = tcell.NewEventKey(tcell.KeyDown, ' ', tcell.ModNone)
 			}
@@ -720,7 +1693,16 @@ func createApp(doc Document) *tview.Application {
 			return overlayPopup.HandleInput(event)
 		}
 
+		if searchInput.HasFocus() {
+			return event
+		}
+
 		if filterInput.HasFocus() {
+			if event.Key() == tcell.KeyEnter || event.Key() == tcell.KeyEscape {
+				// Both close the autocomplete list
+				completions.Hide()
+			}
+
 			if event.Key() == tcell.KeyEnter && event.Modifiers() == tcell.ModNone {
 				// Let tview process Enter first so autocomplete selections work.
 				return event
@@ -773,10 +1755,89 @@ func createApp(doc Document) *tview.Application {
 			return nil
 		}
 
+		if keymap.CopyFilter.Matches(event) {
+			copyText("filter", filterInput.GetText())
+			return nil
+		}
+
+		if keymap.CopyOutput.Matches(event) {
+			mutex.Lock()
+			d := doc.WithFilter(doc.filter)
+			mutex.Unlock()
+
+			go func() {
+				d.options.ForceColor = false
+				d.options.Monochrome = true
+
+				var buf bytes.Buffer
+				_, err := d.WriteTo(&buf)
+				app.QueueUpdateDraw(func() {
+					if err != nil && buf.Len() == 0 {
+						showHelpMessage("No output to copy")
+						return
+					}
+
+					copyText("output", buf.String())
+				})
+			}()
+
+			return nil
+		}
+
+		if keymap.CopyValue.Matches(event) || keymap.CopyPath.Matches(event) {
+			var (
+				path []any
+				d    Document
+			)
+
+			switch {
+			case inputBox.HasFocus():
+				mutex.Lock()
+				input := doc.input
+				mutex.Unlock()
+
+				path, d = inputPath, inputDocument(input)
+			case outputBox.HasFocus():
+				mutex.Lock()
+				d = doc.WithFilter(doc.filter)
+				mutex.Unlock()
+
+				path = outputPath
+			default:
+				return event
+			}
+
+			if path == nil {
+				showHelpMessage("No value is selected")
+				return nil
+			}
+
+			if keymap.CopyPath.Matches(event) {
+				copyText("path", jqPath(path))
+				return nil
+			}
+
+			go func() {
+				values, _ := d.values()
+				v, ok := valueAt(values, path)
+				app.QueueUpdateDraw(func() {
+					if !ok {
+						showHelpMessage("The selected value is no longer shown")
+						return
+					}
+
+					copyText("value", formatValue(v, d.options))
+				})
+			}()
+
+			return nil
+		}
+
 		if event.Key() == tcell.KeyCtrlC {
 			if filterInput.HasFocus() && len(filterInput.GetText()) > 0 {
-				filterInput.SetText("")
+				setFilterText("")
 			} else {
+				*status = exitCancelled
 				app.Stop()
 			}
 
@@ -791,14 +1852,18 @@ func createApp(doc Document) *tview.Application {
 			return tcell.NewEventKey(tcell.KeyUp, ' ', tcell.ModNone)
 		}
 
+		_, isTextView := focused.(*tview.TextView)
+		tree, isTreeView := focused.(*tview.TreeView)
+		table, isTable := focused.(*tview.Table)
+
 		if keymap.PageDown.Matches(event) {
-			if _, ok := focused.(*tview.TextView); ok {
+			if isTextView || isTreeView || isTable {
 				return tcell.NewEventKey(tcell.KeyPgDn, ' ', tcell.ModNone)
 			}
 		}
 
 		if keymap.PageUp.Matches(event) {
-			if _, ok := focused.(*tview.TextView); ok {
+			if isTextView || isTreeView || isTable {
 				return tcell.NewEventKey(tcell.KeyPgUp, ' ', tcell.ModNone)
 			}
 		}
@@ -812,6 +1877,12 @@ func createApp(doc Document) *tview.Application {
 				scrollHorizontally(tv, false)
 				return nil
 			}
+
+			if isTable {
+				row, _ := table.GetSelection()
+				table.Select(row, 0)
+				return nil
+			}
 		}
 
 		if keymap.LineEnd.Matches(event) {
@@ -823,6 +1894,12 @@ func createApp(doc Document) *tview.Application {
 				scrollHorizontally(tv, true)
 				return nil
 			}
+
+			if isTable {
+				row, _ := table.GetSelection()
+				table.Select(row, table.GetColumnCount()-1)
+				return nil
+			}
 		}
 
 		if keymap.HalfPageUp.Matches(event) {
@@ -830,6 +1907,17 @@ func createApp(doc Document) *tview.Application {
 				scrollHalfPage(tv, true)
 				return nil
 			}
+
+			if isTreeView {
+				_, _, _, height := tree.GetInnerRect()
+				tree.Move(-height / 2)
+				return nil
+			}
+
+			if isTable {
+				scrollTableHalfPage(table, true)
+				return nil
+			}
 		}
 
 		if keymap.HalfPageDown.Matches(event) {
@@ -837,6 +1925,17 @@ func createApp(doc Document) *tview.Application {
 				scrollHalfPage(tv, false)
 				return nil
 			}
+
+			if isTreeView {
+				_, _, _, height := tree.GetInnerRect()
+				tree.Move(height / 2)
+				return nil
+			}
+
+			if isTable {
+				scrollTableHalfPage(table, false)
+				return nil
+			}
 		}
 
 		if keymap.CursorRight.Matches(event) {
@@ -853,13 +1952,13 @@ func createApp(doc Document) *tview.Application {
 
 		if keymap.FocusInputPane.Matches(event) {
 			if !doc.options.HideInputPane {
-				app.SetFocus(inputView)
+				app.SetFocus(inputBox)
 				return nil
 			}
 		}
 
 		if keymap.FocusOutputPane.Matches(event) {
-			app.SetFocus(outputView)
+			app.SetFocus(outputBox)
 			return nil
 		}
 
@@ -869,25 +1968,209 @@ func createApp(doc Document) *tview.Application {
 		}
 
 		if keymap.NextFocus.Matches(event) {
-			if inputView.HasFocus() {
-				app.SetFocus(outputView)
+			if inputBox.HasFocus() {
+				app.SetFocus(outputBox)
 				return nil
 			}
 
-			if outputView.HasFocus() {
+			if outputBox.HasFocus() && snapshots.visible {
+				app.SetFocus(snapshots.view)
+				return nil
+			}
+
+			if outputBox.HasFocus() || snapshots.view.HasFocus() {
 				app.SetFocus(filterInput)
 				return nil
 			}
 		}
 
 		if keymap.PreviousFocus.Matches(event) {
-			if inputView.HasFocus() {
+			if inputBox.HasFocus() {
+				app.SetFocus(filterInput)
+				return nil
+			}
+
+			if outputBox.HasFocus() {
+				app.SetFocus(inputBox)
+				return nil
+			}
+
+			if snapshots.view.HasFocus() {
+				app.SetFocus(outputBox)
+				return nil
+			}
+		}
+
+		if keymap.InsertPath.Matches(event) {
+			if inputBox.HasFocus() && inputPath != nil {
+				setFilterText(insertPath(filterInput.GetText(), jqPath(inputPath)))
 				app.SetFocus(filterInput)
 				return nil
 			}
+		}
+
+		if keymap.ToggleTreeView.Matches(event) {
+			if inputBox.HasFocus() {
+				toggleView(inputBox, inputView, inputTree.view, func() {
+					mutex.Lock()
+					input := doc.input
+					mutex.Unlock()
+
+					go func() {
+						renderInput(input)
+						app.Draw()
+					}()
+				})
+
+				return nil
+			}
+
+			if outputBox.HasFocus() {
+				toggleView(outputBox, outputView, outputTree.view, renderOutput)
+				return nil
+			}
+		}
+
+		if keymap.ToggleTableView.Matches(event) {
+			if outputBox.HasFocus() {
+				toggleView(outputBox, outputView, outputTable.view, renderOutput)
+				return nil
+			}
+		}
+
+		if keymap.ToggleDiffView.Matches(event) {
+			if outputBox.HasFocus() {
+				toggleView(outputBox, outputView, outputDiff.view, renderOutput)
+				return nil
+			}
+		}
+
+		if keymap.ToggleDiffBase.Matches(event) {
+			if outputBox.HasFocus() {
+				if outputDiff.Base() == "" {
+					mutex.Lock()
+					outputDiff.SetBase(doc.filter)
+					mutex.Unlock()
+				} else {
+					outputDiff.SetBase("")
+				}
+
+				renderOutput()
+				return nil
+			}
+		}
+
+		if keymap.PinSnapshot.Matches(event) {
+			if inputBox.HasFocus() || outputBox.HasFocus() || snapshots.view.HasFocus() {
+				overlayPopup.OpenPinPrompt()
+				return nil
+			}
+		}
+
+		if keymap.ToggleSnapshot.Matches(event) {
+			if inputBox.HasFocus() || outputBox.HasFocus() || snapshots.view.HasFocus() {
+				snapshots.Toggle()
+				updateSnapshotPane()
+				return nil
+			}
+		}
+
+		if isTable && table == outputTable.view {
+			if keymap.SortColumn.Matches(event) {
+				outputTable.SortSelected()
+				return nil
+			}
+
+			if keymap.HideColumn.Matches(event) {
+				outputTable.HideSelected()
+				return nil
+			}
+
+			if keymap.ShowColumns.Matches(event) {
+				outputTable.ShowColumns()
+				return nil
+			}
+		}
+
+		if isTreeView {
+			var t *jsonTree
+			switch tree {
+			case inputTree.view:
+				t = inputTree
+			case outputTree.view:
+				t = outputTree
+			}
+
+			if expand, collapse := keymap.ExpandNode.Matches(event), keymap.CollapseNode.Matches(event); t != nil && (expand || collapse) {
+				if node := tree.GetCurrentNode(); node != nil {
+					t.SetExpanded(node, expand)
+				}
+
+				return nil
+			}
+		}
+
+		focusedPane := (*pane)(nil)
+		switch focused {
+		case inputView:
+			focusedPane = &inputPane
+		case outputView:
+			focusedPane = &outputPane
+		}
+
+		if forward, backward := keymap.SearchForward.Matches(event), keymap.SearchBackward.Matches(event); forward || backward {
+			if focusedPane != nil {
+				openSearch(focusedPane, backward)
+				return nil
+			}
+		}
+
+		if next, previous := keymap.NextMatch.Matches(event), keymap.PreviousMatch.Matches(event); next || previous {
+			if focusedPane != nil {
+				focusedPane.NextMatch(previous)
+				return nil
+			}
+		}
+
+		if keymap.ToggleOriginalInput.Matches(event) {
+			mutex.Lock()
+			input, original := doc.input, doc.original
+			mutex.Unlock()
+
+			if original != "" {
+				showOriginal.Store(!showOriginal.Load())
+				go func() {
+					renderInput(input)
+					app.Draw()
+				}()
+			}
+
+			return nil
+		}
+
+		if nextTab, previousTab := keymap.NextTab.Matches(event), keymap.PreviousTab.Matches(event); nextTab || previousTab {
+			mutex.Lock()
+			tabbed := bool(doc.options.Tabs) && len(doc.tabs) > 1
+			mutex.Unlock()
+
+			if tabbed {
+				delta := 1
+				if previousTab {
+					delta = -1
+				}
+
+				var input string
+				queueDocumentUpdate(func(next *Document) {
+					next.selectTab(next.selected + delta)
+					input = next.input
+					inputGeneration++
+				})
+
+				go func() {
+					renderInput(input)
+					app.Draw()
+				}()
 
-			if outputView.HasFocus() {
-				app.SetFocus(inputView)
 				return nil
 			}
 		}
@@ -898,18 +2181,18 @@ func createApp(doc Document) *tview.Application {
 			mutex.Unlock()
 
 			if !hidden {
-				if inputView.HasFocus() {
-					app.SetFocus(outputView)
+				if inputBox.HasFocus() {
+					app.SetFocus(outputBox)
 				}
 
-				viewFlex.ResizeItem(inputView, 0, 0)
+				viewFlex.ResizeItem(inputBox, 0, 0)
 				mutex.Lock()
 				doc.options.HideInputPane = true
 				mutex.Unlock()
 				return nil
 			}
 
-			viewFlex.ResizeItem(inputView, 0, 1)
+			viewFlex.ResizeItem(inputBox, 0, 1)
 			mutex.Lock()
 			doc.options.HideInputPane = false
 			mutex.Unlock()
@@ -926,10 +2209,14 @@ func createApp(doc Document) *tview.Application {
 				app.ForceDraw()
 				return nil
 			}
+
+			if isTreeView || isTable {
+				return tcell.NewEventKey(tcell.KeyEnd, ' ', tcell.ModNone)
+			}
 		}
 
 		if keymap.ScrollToTop.Matches(event) {
-			if _, ok := focused.(*tview.TextView); ok {
+			if isTextView || isTreeView || isTable {
 				return tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone)
 			}
 		}
@@ -944,13 +2231,54 @@ func createApp(doc Document) *tview.Application {
 			tty.Write([]byte("\x1b[?2026h"))
 		}
 
-		updateScrollIndicator("Input", int(inputLineCount.Load()), inputView)
-		updateScrollIndicator("Output", int(outputLineCount.Load()), outputView)
+		// Copy text to the clipboard with an OSC 52 escape sequence
+		if clip := pendingCopy; clip != nil {
+			pendingCopy = nil
+
+			var w io.Writer
+			if ok {
+				w = tty
+			}
+
+			if err := writeOSC52(w, clip.text); err != nil {
+				showHelpMessage(fmt.Sprintf("Failed to copy %s: %s", clip.what, err))
+			} else {
+				showHelpMessage(fmt.Sprintf("Copied %s to the clipboard", clip.what))
+			}
+		}
+
+		mutex.Lock()
+		source := doc.describeSource()
+		format := doc.options.InputFormat
+		mutex.Unlock()
+
+		inputTitle := "Input"
+		if source != "" {
+			inputTitle += " · " + source
+		}
+
+		if showOriginal.Load() {
+			inputTitle += fmt.Sprintf(" · %s", format)
+		}
+
+		if t := inputReloaded.Load(); t != nil {
+			inputTitle += fmt.Sprintf(" · reloaded %s", t.Format(time.TimeOnly))
+		}
+
+		updateScrollIndicator(inputTitle, int(inputLineCount.Load()), inputView, inputPane.MatchStatus())
+		updateScrollIndicator("Output", int(outputLineCount.Load()), outputView, outputPane.MatchStatus())
+		updateTreeScrollIndicator(inputTitle, inputTree.view)
+		updateTreeScrollIndicator("Output", outputTree.view)
+		updateTableScrollIndicator("Output", outputTable)
+		updateScrollIndicator("Output", outputDiff.lines, outputDiff.view, outputDiff.Status())
+		snapshots.updateTitle()
 
 		return false
 	})
 
 	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		completions.DrawHint(screen, filterInput)
+
 		// Finish a synchronized update
 		tty, ok := screen.Tty()
 		if ok {
@@ -982,41 +2310,75 @@ func main() {
 		JQCommand:     config.JQCommand,
 		HideInputPane: config.HideInputPane,
 		LibraryPaths:  config.LibraryPaths,
+		Engine:        config.Engine,
 	}
 
 	filter, args := parseArgs(&options)
 
-	if _, err := exec.LookPath(string(options.JQCommand)); err != nil {
-		log.Fatalf("%s is not installed or could not be found: %s\n", options.JQCommand, err)
+	if options.Engine == "exec" {
+		if _, err := exec.LookPath(string(options.JQCommand)); err != nil {
+			log.Fatalf("%s is not installed or could not be found: %s\n", options.JQCommand, err)
+		}
 	}
 
-	doc := Document{filter: filter, options: options, config: config}
+	doc := Document{
+		filter:    filter,
+		options:   options,
+		config:    config,
+		evaluator: newEvaluator(options),
+	}
 
 	if !options.NullInput {
-		var in io.Reader = os.Stdin
 		if len(args) > 0 {
-			var files []io.Reader
-			for _, fname := range args {
-				f, err := os.Open(fname)
+			tabs, err := readTabs(args)
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			doc.tabs = tabs
+			doc.files = args
+		} else {
+			if options.Follow {
+				// Values are read as they arrive, so stdin is not
+				// checked for compression, which would wait for its
+				// first bytes
+				doc.follow = os.Stdin
+			} else {
+				stdin, compression, err := decompress(os.Stdin)
 				if err != nil {
 					log.Fatalln(err)
 				}
 
-				defer f.Close()
+				doc.source = describeInput([]string{"stdin"}, []string{compression})
+				if _, err := doc.ReadFrom(stdin); err != nil {
+					log.Fatalln(err)
+				}
+			}
+		}
 
-				files = append(files, f)
+		if doc.options.InputFormat == "" {
+			doc.options.InputFormat = "json"
+			if len(args) > 0 {
+				doc.options.InputFormat = detectInputFormat(args[0])
 			}
+		}
 
-			in = io.MultiReader(files...)
+		if len(doc.tabs) > 0 {
+			err = doc.convertTabs()
+		} else {
+			err = doc.convertInput(doc.input)
 		}
 
-		if _, err := doc.ReadFrom(in); err != nil {
+		if err != nil {
 			log.Fatalln(err)
 		}
 	}
 
-	app := createApp(doc)
+	var status int
+	app := createApp(doc, &status)
 	if err := app.Run(); err != nil {
 		log.Fatalln(err)
 	}
+
+	os.Exit(status)
 }
@@ -68,6 +68,51 @@ func TestDocumentWriteTo(t *testing.T) {
 	assert.Equal(t, testMsg, buffer.String())
 }
 
+func TestDocumentWriteToUsesEvaluator(t *testing.T) {
+	doc := &Document{
+		input:     `{"foo":"bar"}`,
+		filter:    ".foo",
+		options:   options.Options{RawOutput: true},
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		ctx:       context.Background(),
+	}
+
+	buffer := bytes.Buffer{}
+
+	_, err := doc.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar\n", buffer.String())
+}
+
+func TestDocumentWriteToConvertsOutput(t *testing.T) {
+	doc := &Document{
+		input:     `[{"n":1,"name":"a"},{"n":22,"name":"b"}]`,
+		filter:    ".",
+		options:   options.Options{OutputFormat: "table", RawOutput: true},
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		ctx:       context.Background(),
+	}
+
+	buffer := bytes.Buffer{}
+	_, err := doc.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "n   name\n--  ----\n1   a\n22  b\n", buffer.String())
+
+	doc.filter = ".[] | .n"
+	doc.options.OutputFormat = "yaml"
+	buffer.Reset()
+	_, err = doc.WriteTo(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n---\n22\n", buffer.String())
+
+	doc.filter = "error(\"boom\")"
+	buffer.Reset()
+	_, err = doc.WriteTo(&buffer)
+	stderr, ok := errorOutput(err)
+	assert.True(t, ok)
+	assert.Contains(t, stderr, "boom")
+}
+
 func TestDocumentWithFilterPreservesFields(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -132,6 +177,41 @@ func TestBuildMainHelpTextUsesConfiguredBindings(t *testing.T) {
 	assert.Contains(t, help, "Ctrl-s")
 }
 
+func TestBuildPathHelpText(t *testing.T) {
+	keymap := DefaultKeymap()
+
+	help := buildPathHelpText(keymap, `.a["[x]"]`)
+	assert.True(t, strings.HasPrefix(help, `[::b].a["[x[]"][::-]   [::d]p[::-] [::b]insert path[::-]`), help)
+	assert.Contains(t, help, "Ctrl-C")
+
+	keymap.InsertPath = nil
+	assert.NotContains(t, buildPathHelpText(keymap, ".a"), "insert path")
+}
+
+func TestSaveOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	doc := Document{
+		input:     `{"b":"x","a":[1]}`,
+		filter:    ".b",
+		options:   options.Options{RawOutput: true},
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		ctx:       context.Background(),
+	}
+
+	require.NoError(t, saveOutput(doc, path))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+
+	// A failing filter leaves the file as it is
+	err = saveOutput(doc.WithFilter(`error("boom")`), path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+}
+
 func TestParseArgsLoadsFilterFromFile(t *testing.T) {
 	filterFile := filepath.Join(t.TempDir(), "filter.jq")
 	require.NoError(t, os.WriteFile(filterFile, []byte(".foo\n"), 0o644))
@@ -177,6 +257,21 @@ func TestParseArgsTreatsFirstArgAsFilterWhenMultiplePositionals(t *testing.T) {
 	assert.Equal(t, []string{"file1.json", "file2.json"}, args)
 }
 
+func TestParseArgsPositionalArgs(t *testing.T) {
+	oldArgs := os.Args
+	os.Args = []string{"ijq", "-n", "--args", "$ARGS.positional", "a", "b"}
+	t.Cleanup(func() {
+		os.Args = oldArgs
+	})
+
+	opts := options.Options{}
+	filter, args := parseArgs(&opts)
+
+	assert.Equal(t, "$ARGS.positional", filter)
+	assert.Empty(t, args)
+	assert.Equal(t, options.Positional{"a", "b"}, opts.Positional)
+}
+
 func TestParseArgsVersionFlagPrintsAndExits(t *testing.T) {
 	if os.Getenv("IJQ_PARSEARGS_VERSION_HELPER") == "1" {
 		oldArgs := os.Args
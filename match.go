@@ -0,0 +1,215 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// AutocompleteMatch is how completions are matched against the word typed in
+// the filter.
+type AutocompleteMatch string
+
+const (
+	// matchPrefix matches completions which start with the word
+	matchPrefix AutocompleteMatch = "prefix"
+
+	// matchSubstring matches completions which contain the word
+	matchSubstring AutocompleteMatch = "substring"
+
+	// matchFuzzy matches completions which contain the characters of the
+	// word in order, e.g. fn matches first_name
+	matchFuzzy AutocompleteMatch = "fuzzy"
+)
+
+func (m *AutocompleteMatch) UnmarshalText(text []byte) error {
+	switch value := AutocompleteMatch(text); value {
+	case matchPrefix, matchSubstring, matchFuzzy:
+		*m = value
+		return nil
+	}
+
+	return fmt.Errorf("invalid value %q: must be one of prefix, substring, fuzzy", text)
+}
+
+// Scores of the characters of a match. A match scores higher when its
+// characters start words of the completion and follow each other.
+const (
+	scoreChar        = 10
+	scoreStart       = 10
+	scoreBoundary    = 6
+	scoreConsecutive = 8
+	penaltyGap       = 3
+	maxPenaltyGap    = 10
+)
+
+// candidateMatch is a completion which matches the word being typed.
+type candidateMatch struct {
+	// index is the position of the completion among the candidates
+	index int
+	score int
+
+	// positions are the byte offsets of the matched characters in the
+	// completion
+	positions []int
+}
+
+// match reports whether candidate matches word, and returns the match. Prefix
+// matches are case sensitive. Substring and fuzzy matches ignore case unless
+// the word contains an upper case letter.
+func (m AutocompleteMatch) match(candidate, word string) (candidateMatch, bool) {
+	if word == "" {
+		return candidateMatch{}, true
+	}
+
+	c, w := []rune(candidate), []rune(word)
+	foldCase := m != matchPrefix && m != "" && !slices.ContainsFunc(w, unicode.IsUpper)
+	equal := func(i, j int) bool {
+		if foldCase {
+			return unicode.ToLower(c[i]) == unicode.ToLower(w[j])
+		}
+
+		return c[i] == w[j]
+	}
+
+	// matchAt returns the runes of candidate which match the word when its
+	// first rune is matched at start, or nil if it does not match there
+	matchAt := func(start int, contiguous bool) []int {
+		runes := []int{start}
+		for i, j := start+1, 1; j < len(w); i++ {
+			if i >= len(c) || (contiguous && !equal(i, j)) {
+				return nil
+			}
+
+			if equal(i, j) {
+				runes = append(runes, i)
+				j++
+			}
+		}
+
+		return runes
+	}
+
+	best, found := candidateMatch{}, false
+	for start := range c {
+		if !equal(start, 0) {
+			continue
+		}
+
+		var runes []int
+		switch m {
+		case matchSubstring:
+			runes = matchAt(start, true)
+		case matchFuzzy:
+			runes = matchAt(start, false)
+		default:
+			if start == 0 {
+				runes = matchAt(start, true)
+			}
+		}
+
+		if runes == nil {
+			continue
+		}
+
+		if score := scoreRunes(c, runes); !found || score > best.score {
+			best, found = candidateMatch{score: score, positions: runeOffsets(candidate, runes)}, true
+		}
+	}
+
+	return best, found
+}
+
+// matchAll returns the matches of the candidates which match word, best
+// first. Candidates which match equally well are ordered by weight, which may
+// be nil, and then by their order in candidates.
+func (m AutocompleteMatch) matchAll(candidates []string, word string, weight func(index int) int) []candidateMatch {
+	var matches []candidateMatch
+	for i, candidate := range candidates {
+		if match, ok := m.match(candidate, word); ok {
+			match.index = i
+			matches = append(matches, match)
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b candidateMatch) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 || weight == nil {
+			return c
+		}
+
+		return cmp.Compare(weight(b.index), weight(a.index))
+	})
+
+	return matches
+}
+
+// scoreRunes returns the score of the runes at the given indices of c.
+func scoreRunes(c []rune, runes []int) int {
+	score := -min(runes[0], maxPenaltyGap)
+	for k, i := range runes {
+		score += scoreChar
+		switch {
+		case i == 0:
+			score += scoreStart
+		case isWordStart(c, i):
+			score += scoreBoundary
+		}
+
+		if k > 0 {
+			if gap := i - runes[k-1] - 1; gap == 0 {
+				score += scoreConsecutive
+			} else {
+				score -= min(gap*penaltyGap, maxPenaltyGap)
+			}
+		}
+	}
+
+	return score
+}
+
+// isWordStart reports whether the rune at i starts a word of c, e.g. the n of
+// first_name or of firstName.
+func isWordStart(c []rune, i int) bool {
+	prev := c[i-1]
+	if unicode.IsLower(prev) && unicode.IsUpper(c[i]) {
+		return true
+	}
+
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(c[i]) || unicode.IsDigit(c[i]))
+}
+
+// runeOffsets returns the byte offsets in s of the runes at the given
+// indices.
+func runeOffsets(s string, runes []int) []int {
+	offsets := make([]int, 0, len(runes))
+	i, offset := 0, 0
+	for _, r := range runes {
+		for ; i < r; i++ {
+			_, size := utf8.DecodeRuneInString(s[offset:])
+			offset += size
+		}
+
+		offsets = append(offsets, offset)
+	}
+
+	return offsets
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutocompleteMatch(t *testing.T) {
+	for _, tt := range []struct {
+		mode      AutocompleteMatch
+		candidate string
+		word      string
+		positions []int
+		ok        bool
+	}{
+		{matchPrefix, "first_name", "", nil, true},
+		{matchPrefix, "first_name", "fir", []int{0, 1, 2}, true},
+		{matchPrefix, "first_name", "name", nil, false},
+		{matchPrefix, "first_name", "Fir", nil, false},
+		{matchSubstring, "first_name", "name", []int{6, 7, 8, 9}, true},
+		{matchSubstring, "first_name", "NAME", nil, false},
+		{matchSubstring, "first_Name", "name", []int{6, 7, 8, 9}, true},
+		{matchSubstring, "first_name", "fn", nil, false},
+		{matchFuzzy, "first_name", "fn", []int{0, 6}, true},
+		{matchFuzzy, "first_name", "nf", nil, false},
+		{matchFuzzy, "firstName", "fN", []int{0, 5}, true},
+		{matchFuzzy, "firstname", "fN", nil, false},
+		// The best match is chosen, here the n starting a word
+		{matchFuzzy, "unknown_name", "nam", []int{8, 9, 10}, true},
+		// Positions are byte offsets
+		{matchFuzzy, "ünïcode", "nc", []int{2, 5}, true},
+	} {
+		m, ok := tt.mode.match(tt.candidate, tt.word)
+		if assert.Equal(t, tt.ok, ok, "%s %q %q", tt.mode, tt.candidate, tt.word) && ok {
+			assert.Equal(t, tt.positions, m.positions, "%s %q %q", tt.mode, tt.candidate, tt.word)
+		}
+	}
+}
+
+func TestAutocompleteMatchUnmarshal(t *testing.T) {
+	var m AutocompleteMatch
+	assert.NoError(t, m.UnmarshalText([]byte("fuzzy")))
+	assert.Equal(t, matchFuzzy, m)
+	assert.Error(t, m.UnmarshalText([]byte("exact")))
+}
+
+func TestAutocompleteMatchAll(t *testing.T) {
+	names := func(candidates []string, matches []candidateMatch) []string {
+		var names []string
+		for _, m := range matches {
+			names = append(names, candidates[m.index])
+		}
+
+		return names
+	}
+
+	// Matches at the start of the candidate and of its words, and with
+	// consecutive characters, rank first
+	candidates := []string{"nickname", "first_name", "name", "unnamed", "n_a_m_e"}
+	assert.Equal(t, []string{"name", "first_name", "unnamed", "nickname", "n_a_m_e"}, names(candidates, matchFuzzy.matchAll(candidates, "name", nil)))
+
+	// Candidates which match equally well are ordered by weight
+	candidates = []string{"id", "ident", "idx"}
+	weights := []int{1, 5, 5}
+	assert.Equal(t, []string{"ident", "idx", "id"}, names(candidates, matchFuzzy.matchAll(candidates, "id", func(i int) int { return weights[i] })))
+	assert.Equal(t, candidates, names(candidates, matchPrefix.matchAll(candidates, "id", nil)))
+}
@@ -0,0 +1,505 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+type completionKind int
+
+const (
+	completeNothing   completionKind = iota
+	completeKey                      // .fo
+	completeQuotedKey                // ."fo
+	completeFunction                 // sel, mod::fu
+	completeVariable                 // $fo
+	completeModule                   // import "fo
+)
+
+// completionContext describes what is typed at the cursor of a filter.
+type completionContext struct {
+	kind completionKind
+
+	// start is the offset of the text which is replaced by a completion,
+	// and word is the part of it which is typed. For a quoted key, the
+	// replaced text starts at the quote and word is the unquoted text.
+	start int
+	word  string
+
+	// input is a filter which produces the values a key is completed
+	// from, when applied to the input of the whole filter. directives are
+	// the import and include directives it depends on, which must come
+	// first in any filter it is part of.
+	input      string
+	directives string
+
+	// symbols are the variables and functions defined in the filter which
+	// are in scope at the cursor, innermost first, and imports are the
+	// modules imported or included by the filter.
+	symbols []symbol
+	imports []moduleImport
+}
+
+// moduleImport is an import or include directive of a filter.
+type moduleImport struct {
+	include bool
+	path    string
+
+	// alias is the name the module is imported as, with a leading $ if
+	// it is imported as data
+	alias string
+}
+
+type frameKind int
+
+const (
+	frameTop           frameKind = iota
+	frameParen                   // ( ... )
+	frameCall                    // f( ... ; ... )
+	frameBracket                 // [ ... ] or .[ ... ]
+	frameObject                  // { ... }
+	frameInterpolation           // "\( ... )"
+	frameIf                      // if ... then ... else ... end
+	frameReduce                  // reduce ... as $x, up to its arguments
+	frameReduceArgs              // ( init; update )
+	frameDef                     // def f: ... ;
+	frameParams                  // def f( ... ):
+)
+
+// frame is an expression of a filter which has been opened but not closed
+// before the cursor.
+type frame struct {
+	kind frameKind
+
+	// base is the pipeline which produces the input of the expressions
+	// in the frame, and pipeline the one which produces the input of
+	// the current expression. Both are relative to the input of the
+	// whole filter.
+	base     []string
+	pipeline []string
+
+	// segment is the offset at which the current expression starts
+	segment int
+
+	// bind is true after "as" or "label", whose body gets the input of
+	// the binding rather than its output
+	bind bool
+
+	// The called function and the offset and text of its arguments, for
+	// frameCall and frameReduceArgs. For frameReduce, argStart is the
+	// offset of its source.
+	name     string
+	argStart int
+	args     []string
+
+	// binding is the source and pattern of the reduce or foreach of a
+	// frameReduceArgs, e.g. .[] as $x
+	binding string
+
+	// as is true once a frameReduce has seen its "as"
+	as bool
+
+	// symbols are the variables and functions defined in the frame which
+	// are in scope at the current expression, and bound the variables of
+	// an "as" whose body has not started yet
+	symbols []symbol
+	bound   []symbol
+
+	// The parameters of a frameDef, whose name is in name, and whether its
+	// body has started
+	params []string
+	body   bool
+
+	// directive is true inside an import or include directive
+	directive bool
+}
+
+// keywords are the identifiers which are not function names.
+var keywords = []string{
+	"if", "then", "elif", "else", "end", "as", "def", "reduce", "foreach",
+	"try", "catch", "label", "import", "include", "and", "or", "__loc__",
+}
+
+// elementFunctions are the builtins which evaluate their first argument for
+// each element of their input.
+var elementFunctions = []string{
+	"map", "map_values", "sort_by", "group_by", "unique_by", "min_by",
+	"max_by", "any", "all",
+}
+
+// argumentPipeline returns the pipeline which produces the input of the
+// argument at index of a call to the function name, whose input is produced
+// by pipeline. args holds the preceding arguments.
+func argumentPipeline(name string, index int, pipeline []string, args []string) []string {
+	switch {
+	case index == 0 && slices.Contains(elementFunctions, name):
+		return append(slices.Clip(pipeline), ".[]")
+	case index == 0 && name == "with_entries":
+		return append(slices.Clip(pipeline), "to_entries[]")
+	case index == 0 && (name == "walk" || name == "paths" || name == "recurse"):
+		return append(slices.Clip(pipeline), "..")
+	case index == 1 && (name == "any" || name == "all" || name == "INDEX"):
+		// The condition is applied to the outputs of the generator
+		return append(slices.Clip(pipeline), args[0])
+	}
+
+	return pipeline
+}
+
+// defPrelude returns a filter which defines the function name and its
+// params, so that its body can be evaluated outside of a call. The function
+// and its parameters produce no values.
+func defPrelude(name string, params []string) string {
+	var b strings.Builder
+	b.WriteString("def " + defSymbols(name, params)[0].signature + ": empty; ")
+	for _, param := range params {
+		b.WriteString("def " + strings.TrimPrefix(param, "$") + ": empty; ")
+	}
+
+	for _, param := range params {
+		if strings.HasPrefix(param, "$") {
+			b.WriteString("null as " + param + " | ")
+		}
+	}
+
+	b.WriteString(".")
+	return b.String()
+}
+
+// completionContextAt returns what is typed at cursor, an offset in filter.
+// The filter is parsed up to the cursor to find the input of the expression
+// at the cursor, e.g. the elements of .items inside .items | map(...), and
+// the variables and functions in scope there. The bindings and definitions
+// in scope are kept in the pipeline, so that it can be evaluated.
+func completionContextAt(filter string, cursor int) completionContext {
+	src := blankComments(filter[:cursor])
+	tokens := lex(src)
+
+	var imports []moduleImport
+	var directives []string
+	directiveStart := 0
+	stack := []*frame{{kind: frameTop}}
+	top := func() *frame { return stack[len(stack)-1] }
+	push := func(kind frameKind, t token) *frame {
+		f := &frame{kind: kind, base: top().pipeline, pipeline: top().pipeline, segment: t.end}
+		stack = append(stack, f)
+		return f
+	}
+	pop := func(kinds ...frameKind) bool {
+		if len(stack) == 1 || !slices.Contains(kinds, top().kind) {
+			return false
+		}
+
+		stack = stack[:len(stack)-1]
+		return true
+	}
+
+	// binder returns the frame which binds the variables of a pattern
+	// being typed, such as [$a, {b: $c}] after "as", or nil if no pattern
+	// is being typed
+	binder := func() *frame {
+		for i := len(stack) - 1; i >= 0; i-- {
+			switch f := stack[i]; {
+			case f.kind == frameBracket || f.kind == frameObject:
+				continue
+			case f.bind || (f.kind == frameReduce && f.as):
+				return f
+			}
+
+			break
+		}
+
+		return nil
+	}
+
+	for i, t := range tokens {
+		f := top()
+		segment := strings.TrimSpace(src[f.segment:t.start])
+
+		if f.directive {
+			m := &imports[len(imports)-1]
+			switch {
+			case t.kind == tokenPunct && t.text == ";":
+				f.directive = false
+				f.segment = t.end
+				directives = append(directives, src[directiveStart:t.end])
+			case t.kind == tokenString && m.path == "" && !t.unterminated:
+				_ = json.Unmarshal([]byte(t.text), &m.path)
+			case (t.kind == tokenIdent || t.kind == tokenVariable) && tokens[i-1].text == "as":
+				m.alias = t.text
+			}
+
+			continue
+		}
+
+		switch t.kind {
+		case tokenIdent:
+			switch t.text {
+			case "if":
+				push(frameIf, t)
+			case "then", "elif", "else":
+				if f.kind == frameIf {
+					f.pipeline = f.base
+					f.segment = t.end
+					f.symbols = nil
+				}
+			case "end":
+				pop(frameIf)
+			case "reduce", "foreach":
+				push(frameReduce, t).argStart = t.end
+			case "as":
+				if f.kind == frameReduce {
+					f.as = true
+				} else {
+					f.bind = true
+				}
+			case "label":
+				f.bind = true
+			case "def":
+				push(frameDef, t)
+			case "import", "include":
+				imports = append(imports, moduleImport{include: t.text == "include"})
+				f.directive = true
+				directiveStart = t.start
+			case "and", "or", "catch":
+				f.segment = t.end
+			default:
+				switch {
+				case f.kind == frameDef && f.name == "":
+					f.name = t.text
+				case f.kind == frameParams:
+					def := stack[len(stack)-2]
+					def.params = append(def.params, t.text)
+				}
+			}
+		case tokenVariable:
+			switch {
+			case f.kind == frameParams:
+				def := stack[len(stack)-2]
+				def.params = append(def.params, t.text)
+			case i > 0 && tokens[i-1].text == "label":
+				// Labels are only used by break
+			default:
+				if b := binder(); b == nil {
+					break
+				} else if b.kind == frameReduce {
+					b.symbols = append(b.symbols, variableSymbol(t.text, symbolVariable))
+				} else {
+					b.bound = append(b.bound, variableSymbol(t.text, symbolVariable))
+				}
+			}
+		case tokenStringOpen:
+			push(frameInterpolation, t)
+		case tokenStringMiddle:
+			if f.kind == frameInterpolation {
+				f.pipeline = f.base
+				f.segment = t.end
+				f.symbols = nil
+			}
+		case tokenStringClose:
+			pop(frameInterpolation)
+		case tokenOperator:
+			if t.text == "|=" && segment != "" {
+				// The right side gets the values at the path on
+				// the left
+				f.pipeline = append(slices.Clip(f.pipeline), segment)
+			}
+
+			f.segment = t.end
+		case tokenPunct:
+			switch t.text {
+			case "(":
+				var prev token
+				if i > 0 {
+					prev = tokens[i-1]
+				}
+
+				switch {
+				case f.kind == frameDef && !f.body:
+					push(frameParams, t)
+				case prev.kind == tokenIdent && !slices.Contains(keywords, prev.text):
+					call := push(frameCall, t)
+					call.name, call.argStart = prev.text, t.end
+					call.pipeline = argumentPipeline(call.name, 0, call.base, nil)
+				case f.kind == frameReduce && f.as:
+					args := push(frameReduceArgs, t)
+					args.argStart = t.end
+					args.binding = strings.TrimSpace(src[f.argStart:t.start])
+				default:
+					push(frameParen, t)
+				}
+			case ")":
+				if pop(frameParen, frameCall, frameReduceArgs, frameParams) && top().kind == frameReduce {
+					pop(frameReduce)
+				}
+			case "[":
+				push(frameBracket, t)
+			case "]":
+				pop(frameBracket)
+			case "{":
+				push(frameObject, t)
+			case "}":
+				pop(frameObject)
+			case "|":
+				if f.bind {
+					// The binding is kept for the variables,
+					// and passes its input on
+					f.bind = false
+					f.symbols = append(f.symbols, f.bound...)
+					f.bound = nil
+					f.pipeline = append(slices.Clip(f.pipeline), segment)
+				} else if segment != "" {
+					f.pipeline = append(slices.Clip(f.pipeline), segment)
+				}
+
+				f.segment = t.end
+			case ",", ":":
+				switch {
+				case f.kind == frameObject:
+					// Each key and value of an object gets the
+					// input of the frame
+					f.pipeline = f.base
+					f.symbols = nil
+				case f.kind == frameDef && t.text == ":":
+					// The body of a function gets the input of the
+					// frame, and sees the function and its
+					// parameters
+					f.pipeline = append(slices.Clip(f.base), defPrelude(f.name, f.params))
+					f.body = true
+					f.symbols = append(f.symbols, defSymbols(f.name, f.params)...)
+				}
+
+				f.segment = t.end
+			case ";":
+				switch f.kind {
+				case frameCall, frameReduceArgs:
+					f.args = append(f.args, strings.TrimSpace(src[f.argStart:t.start]))
+					f.argStart = t.end
+					f.symbols = nil
+					if f.kind == frameCall {
+						f.pipeline = argumentPipeline(f.name, len(f.args), f.base, f.args)
+					} else {
+						// The update of reduce and foreach gets the
+						// accumulated value, which starts as init,
+						// with the variables bound
+						f.pipeline = append(slices.Clip(f.base), f.binding, f.args[0])
+					}
+				case frameDef:
+					def := f
+					pop(frameDef)
+					f = top()
+					if def.name != "" {
+						f.symbols = append(f.symbols, defSymbols(def.name, def.params)[0])
+					}
+
+					// The definition is kept for the function,
+					// and passes its input on
+					f.pipeline = append(slices.Clip(f.pipeline), strings.TrimSpace(src[f.segment:t.end])+" .")
+				default:
+					f.pipeline = f.base
+				}
+
+				f.segment = t.end
+			}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return completionContext{}
+	}
+
+	f := top()
+	last := tokens[len(tokens)-1]
+	if last.end != len(src) {
+		return completionContext{}
+	}
+
+	// input returns the filter producing the input of path, the text of
+	// the current expression up to the key being completed
+	input := func(end int) string {
+		pipeline := f.pipeline
+		if path := strings.TrimSpace(src[f.segment:end]); path != "" {
+			pipeline = append(slices.Clip(pipeline), path)
+		}
+
+		if len(pipeline) == 0 {
+			return "."
+		}
+
+		return strings.Join(pipeline, " | ")
+	}
+
+	// symbols returns the symbols in scope, innermost first
+	symbols := func() []symbol {
+		var symbols []symbol
+		for i := len(stack) - 1; i >= 0; i-- {
+			for j := len(stack[i].symbols) - 1; j >= 0; j-- {
+				symbols = append(symbols, stack[i].symbols[j])
+			}
+		}
+
+		return symbols
+	}
+
+	var prev token
+	if len(tokens) > 1 {
+		prev = tokens[len(tokens)-2]
+	}
+
+	switch last.kind {
+	case tokenField:
+		return completionContext{kind: completeKey, start: last.start + 1, word: last.text[1:], input: input(last.start), directives: strings.Join(directives, " ")}
+	case tokenDot:
+		return completionContext{kind: completeKey, start: last.end, input: input(last.start), directives: strings.Join(directives, " ")}
+	case tokenString:
+		if !last.unterminated {
+			break
+		}
+
+		word := last.text[1:]
+		var unquoted string
+		if err := json.Unmarshal([]byte(last.text+`"`), &unquoted); err == nil {
+			word = unquoted
+		}
+
+		switch {
+		case prev.kind == tokenIdent && (prev.text == "import" || prev.text == "include"):
+			return completionContext{kind: completeModule, start: last.start, word: word}
+		case prev.kind == tokenDot && prev.end == last.start:
+			return completionContext{kind: completeQuotedKey, start: last.start, word: word, input: input(prev.start), directives: strings.Join(directives, " ")}
+		}
+	case tokenIdent:
+		if f.directive || (f.kind == frameDef && !f.body) || f.kind == frameParams {
+			// Names being defined are not completed
+			break
+		}
+
+		return completionContext{kind: completeFunction, start: last.start, word: last.text, symbols: symbols(), imports: imports}
+	case tokenVariable:
+		if f.directive || f.kind == frameParams {
+			break
+		}
+
+		return completionContext{kind: completeVariable, start: last.start + 1, word: last.text[1:], symbols: symbols(), imports: imports}
+	}
+
+	return completionContext{}
+}
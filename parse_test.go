@@ -0,0 +1,183 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func TestCompletionContextAtKeys(t *testing.T) {
+	for filter, want := range map[string]string{
+		".":                                  ".",
+		".fo":                                ".",
+		".a.b.":                              ".a.b",
+		".a[].":                              ".a[]",
+		".a?.":                               ".a?",
+		".a[0].":                             ".a[0]",
+		".[] | .":                            ".[]",
+		".a | map(.":                         ".a | .[]",
+		".a | map(.b | .":                    ".a | .[] | .b",
+		".a | select(.":                      ".a",
+		"{a: .":                              ".",
+		".a | {a: .b | .c, d: .":             ".a",
+		".a | [.b, .":                        ".a",
+		".a | .b[.":                          ".a",
+		".a as $x | .":                       ".a as $x",
+		".a |= (.":                           ".a",
+		".a = .":                             ".",
+		"if .a then .b | . else .":           ".",
+		"if .a then .b | .":                  ".b",
+		".a | with_entries(.":                ".a | to_entries[]",
+		".a | any(.b; .":                     ".a | .b",
+		"reduce .[] as $x (.a; .":            ".[] as $x | .a",
+		`.a | "\(.b | .`:                     ".a | .b",
+		"def f: .a | .; .b | .":              "def f: .a | .; . | .b",
+		"def f: .a | .":                      "def f: empty; . | .a",
+		".a # comment\n| .":                  ".a",
+		".a | map(.b) | .":                   ".a | map(.b)",
+		".a | sort_by(.b)[0].":               ".a | sort_by(.b)[0]",
+		".a + .":                             ".",
+		".a | (.b | .c), .":                  ".a",
+		"try .a catch .":                     ".",
+		"label $out | .a | .":                "label $out | .a",
+		".a | first(.b | select(.c) | .":     ".a | .b | select(.c)",
+		"[.[] | {name: .a}] | map(.":         "[.[] | {name: .a}] | .[]",
+		".a | map(select(.b) | .":            ".a | .[] | select(.b)",
+		`.a | map("\(.b)") | .`:              `.a | map("\(.b)")`,
+		".a | map(.b) | map(.c) | select(.d": ".a | map(.b) | map(.c)",
+	} {
+		c := completionContextAt(filter, len(filter))
+		if assert.Contains(t, []completionKind{completeKey, completeQuotedKey}, c.kind, filter) {
+			assert.Equal(t, want, c.input, filter)
+		}
+	}
+}
+
+func TestCompletionContextAtScopes(t *testing.T) {
+	// The variables and functions the filter defines are kept in the
+	// input, so that the keys can be looked up
+	for filter, want := range map[string]map[string]int{
+		".a as $x | $x.":                     {"b": 1},
+		".a as {b: $x} | $x.":                {"c": 1},
+		"def f: .a; f | .":                   {"b": 1},
+		"def f: .a; .a | f.":                 {},
+		"reduce .[] as $x (0; . + $x.":       {"b": 1},
+		"foreach .[] as $x (0; .; $x.":       {"b": 1},
+		"def f($p): $p.":                     {},
+		"def f(g): .a | g | .":               {},
+		"def f: .a as $x | def g: $x; g | .": {"b": 1},
+	} {
+		c := completionContextAt(filter, len(filter))
+		require.Equal(t, completeKey, c.kind, filter)
+
+		doc := Document{
+			input:     `{"a":{"b":{"c":1}},"n":2}`,
+			filter:    keysFilter(c.directives, c.input),
+			evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+			ctx:       context.Background(),
+		}
+
+		var buf bytes.Buffer
+		_, err := doc.WriteTo(&buf)
+		require.NoError(t, err, filter)
+
+		keys, err := decodeKeys(buf.Bytes())
+		require.NoError(t, err, filter)
+		assert.Equal(t, want, keys, "%s: %s", filter, c.input)
+	}
+}
+
+func TestCompletionContextAt(t *testing.T) {
+	filter := ".a | map(.b"
+	assert.Equal(t, completionContext{kind: completeKey, start: 10, word: "b", input: ".a | .[]"}, completionContextAt(filter, len(filter)))
+
+	filter = `.a | ."b c`
+	assert.Equal(t, completionContext{kind: completeQuotedKey, start: 6, word: "b c", input: ".a"}, completionContextAt(filter, len(filter)))
+
+	filter = ".a | to_en"
+	assert.Equal(t, completionContext{kind: completeFunction, start: 5, word: "to_en"}, completionContextAt(filter, len(filter)))
+
+	filter = ".a | $na"
+	assert.Equal(t, completionContext{kind: completeVariable, start: 6, word: "na"}, completionContextAt(filter, len(filter)))
+
+	// Completions are at the cursor, with the text after it ignored
+	filter = ".a | map(.b) | .c"
+	assert.Equal(t, completionContext{kind: completeKey, start: 10, word: "b", input: ".a | .[]"}, completionContextAt(filter, 11))
+	assert.Equal(t, completionContext{kind: completeFunction, start: 5, word: "ma"}, completionContextAt(filter, 7))
+
+	filter = "mod::fun"
+	assert.Equal(t, completionContext{kind: completeFunction, word: "mod::fun"}, completionContextAt(filter, len(filter)))
+
+	filter = `import "mod`
+	assert.Equal(t, completionContext{kind: completeModule, start: 7, word: "mod"}, completionContextAt(filter, len(filter)))
+
+	// Nothing is completed inside strings and comments, after a space or
+	// in numbers
+	for _, filter := range []string{`"a.b`, `"a.b" | "sel`, ".a # .b", ".a ", "1e", ".a | 12", "@bas", ""} {
+		assert.Equal(t, completeNothing, completionContextAt(filter, len(filter)).kind, filter)
+	}
+}
+
+func TestCompletionContextAtSymbols(t *testing.T) {
+	for filter, want := range map[string][]string{
+		". as $x | $":                              {"$x"},
+		". as [$a, {b: $b}] | $":                   {"$b", "$a"},
+		". as $x | (. as $y | .), $":               {"$x"},
+		"reduce .[] as $x (0; $":                   {"$x"},
+		"reduce .[] as $x (0; .) | $":              nil,
+		"foreach .[] as [$k, $v] (0; .; $":         {"$v", "$k"},
+		". as $x | {a: . as $y | $y, b: $":         {"$x"},
+		"if . as $x | $x then $":                   nil,
+		"label $out | $":                           nil,
+		". as $x | f($x; $":                        {"$x"},
+		"def f($a; g): $":                          {"g", "a", "$a", "f($a; g)"},
+		"def f($a; g): .; def h: .; $":             {"h", "f($a; g)"},
+		"def f: def g: .; $":                       {"g", "f"},
+		"def f: def g: .; .; $":                    {"f"},
+		`import "a" as a; import "b" as $b; . | $`: nil,
+	} {
+		c := completionContextAt(filter, len(filter))
+		require.Equal(t, completeVariable, c.kind, filter)
+
+		var symbols []string
+		for _, s := range c.symbols {
+			symbols = append(symbols, s.signature)
+		}
+
+		assert.Equal(t, want, symbols, filter)
+	}
+
+	filter := `import "a" as a; include "b" {search: "./"}; import "c" as $c; $`
+	assert.Equal(t, []moduleImport{
+		{path: "a", alias: "a"},
+		{include: true, path: "b"},
+		{path: "c", alias: "$c"},
+	}, completionContextAt(filter, len(filter)).imports)
+
+	// Names being defined are not completed
+	for _, filter := range []string{"def fo", "def f(g", "def f($x", `import "a" as al`} {
+		assert.Equal(t, completeNothing, completionContextAt(filter, len(filter)).kind, filter)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pathIdentifier matches object keys which can be written after a dot in a
+// jq path without quoting.
+var pathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jqPath returns the jq expression for a path as used in a tree, whose first
+// element is the index of the top-level value. The index of the top-level
+// value is not part of the expression.
+func jqPath(path []any) string {
+	if len(path) <= 1 {
+		return "."
+	}
+
+	var b strings.Builder
+	for i, p := range path[1:] {
+		switch p := p.(type) {
+		case string:
+			if pathIdentifier.MatchString(p) {
+				b.WriteString("." + p)
+				continue
+			}
+
+			if i == 0 {
+				b.WriteByte('.')
+			}
+
+			b.WriteString("[" + quoteJSON(p) + "]")
+		case int:
+			if i == 0 {
+				b.WriteByte('.')
+			}
+
+			b.WriteString("[" + strconv.Itoa(p) + "]")
+		}
+	}
+
+	return b.String()
+}
+
+// linePaths returns the path of the value on each line of text, which holds
+// JSON values as written by jq. A line has the path of the first value or
+// object key which starts on it, or of the object or array which is closed on
+// it. Lines without any of these, and the lines after invalid JSON, have a nil
+// path.
+func linePaths(text string) [][]any {
+	// Offset of the start of each line
+	starts := []int{0}
+	for i := range len(text) {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	paths := make([][]any, len(starts))
+	set := func(offset int, path []any) {
+		line := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+		if paths[line] == nil {
+			paths[line] = append([]any(nil), path...)
+		}
+	}
+
+	// The path of the next value and, for each enclosing object, whether
+	// the next string is a key
+	var (
+		path      []any
+		expectKey []bool
+	)
+
+	next := func() {
+		switch p := path[len(path)-1].(type) {
+		case int:
+			path[len(path)-1] = p + 1
+		case string:
+			expectKey[len(expectKey)-1] = true
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+
+	top := 0
+	for {
+		// The decoder's offset is the end of the previous token, so skip
+		// the separators to find where this one starts
+		offset := int(dec.InputOffset())
+		for offset < len(text) && strings.IndexByte(" \t\r\n,:", text[offset]) >= 0 {
+			offset++
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return paths
+		}
+
+		if len(path) == 0 {
+			path = []any{top}
+			top++
+		}
+
+		inObject := len(expectKey) > 0 && expectKey[len(expectKey)-1]
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				set(offset, path)
+				if tok == '{' {
+					path = append(path, "")
+					expectKey = append(expectKey, true)
+				} else {
+					path = append(path, 0)
+					expectKey = append(expectKey, false)
+				}
+
+				continue
+			case '}', ']':
+				path = path[:len(path)-1]
+				expectKey = expectKey[:len(expectKey)-1]
+				set(offset, path)
+			}
+		case string:
+			if inObject {
+				path[len(path)-1] = tok
+				expectKey[len(expectKey)-1] = false
+				set(offset, path)
+				continue
+			}
+
+			set(offset, path)
+		default:
+			set(offset, path)
+		}
+
+		if len(path) == 1 {
+			path = nil
+			continue
+		}
+
+		next()
+	}
+}
+
+// operandKeywords are the keywords which are followed by an expression.
+var operandKeywords = []string{"if", "then", "elif", "else", "and", "or", "try", "catch", "reduce", "foreach"}
+
+// insertPath returns filter with a path inserted at its end. A filter which
+// only selects its input is replaced by the path. The path becomes the operand
+// of a filter which ends in a pipe, an operator or a keyword which expects
+// one, and is piped from the output of any other filter.
+func insertPath(filter, path string) string {
+	trimmed := strings.TrimSpace(filter)
+	if trimmed == "" || trimmed == "." {
+		return path
+	}
+
+	sep := " "
+	if last, _ := utf8.DecodeLastRuneInString(filter); unicode.IsSpace(last) {
+		sep = ""
+	}
+
+	// A path after a trailing comment goes on the next line
+	tokens, comments := lexComments(filter)
+	if n := len(comments); n > 0 && comments[n-1][1] == len(filter) {
+		sep = "\n"
+	}
+
+	if len(tokens) == 0 {
+		return filter + sep + path
+	}
+
+	switch last := tokens[len(tokens)-1]; {
+	case last.kind == tokenPunct && (last.text == "(" || last.text == "["):
+		if sep == " " {
+			sep = ""
+		}
+
+		return filter + sep + path
+	case last.kind == tokenPunct && strings.Contains("|,;:", last.text),
+		last.kind == tokenOperator,
+		last.kind == tokenIdent && slices.Contains(operandKeywords, last.text):
+		return filter + sep + path
+	}
+
+	return filter + sep + "| " + path
+}
+
+// valueAt returns the value at a path as used in a tree, whose first element
+// is the index of the top-level value.
+func valueAt(values []any, path []any) (any, bool) {
+	var v any = values
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			array, ok := v.([]any)
+			if !ok || p < 0 || p >= len(array) {
+				return nil, false
+			}
+
+			v = array[p]
+		case string:
+			object, ok := v.(orderedObject)
+			if !ok {
+				return nil, false
+			}
+
+			if v, ok = object.get(p); !ok {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJQPath(t *testing.T) {
+	assert.Equal(t, ".", jqPath(nil))
+	assert.Equal(t, ".", jqPath([]any{2}))
+	assert.Equal(t, ".items[3].metadata.labels[\"app.kubernetes.io/name\"]",
+		jqPath([]any{0, "items", 3, "metadata", "labels", "app.kubernetes.io/name"}))
+	assert.Equal(t, ".[0]", jqPath([]any{0, 0}))
+	assert.Equal(t, `.["a b"]._x1["1"]`, jqPath([]any{0, "a b", "_x1", "1"}))
+	assert.Equal(t, `.["\"\\"]`, jqPath([]any{0, `"\`}))
+}
+
+func TestLinePaths(t *testing.T) {
+	text := `{
+  "a": [
+    1,
+    {}
+  ],
+  "b c": "x: {",
+  "d": {
+    "e": null
+  }
+}
+[]
+{"f":[true]} 2
+`
+
+	assert.Equal(t, [][]any{
+		{0},
+		{0, "a"},
+		{0, "a", 0},
+		{0, "a", 1},
+		{0, "a"},
+		{0, "b c"},
+		{0, "d"},
+		{0, "d", "e"},
+		{0, "d"},
+		{0},
+		{1},
+		{2},
+		nil,
+	}, linePaths(text))
+
+	// Lines after invalid JSON have no path
+	assert.Equal(t, [][]any{{0}, nil, nil}, linePaths("1\nx\n2"))
+}
+
+func TestInsertPath(t *testing.T) {
+	assert.Equal(t, ".a", insertPath("", ".a"))
+	assert.Equal(t, ".a", insertPath(" . ", ".a"))
+	assert.Equal(t, "select(.a", insertPath("select(", ".a"))
+	assert.Equal(t, ".b | .a", insertPath(".b | ", ".a"))
+	assert.Equal(t, ".b | .a", insertPath(".b |", ".a"))
+
+	// The path is piped from filters which end in a complete expression
+	assert.Equal(t, ".items | length | .a.b", insertPath(".items | length", ".a.b"))
+	assert.Equal(t, ".x | .a.b", insertPath(".x", ".a.b"))
+	assert.Equal(t, ".x | .a.b", insertPath(".x ", ".a.b"))
+	assert.Equal(t, ".x[0] | .a", insertPath(".x[0]", ".a"))
+	assert.Equal(t, "map(.b) | .a", insertPath("map(.b)", ".a"))
+	assert.Equal(t, "if .b then 1 else 2 end | .a", insertPath("if .b then 1 else 2 end", ".a"))
+
+	// and is its operand where one is expected
+	assert.Equal(t, "[.b, .a", insertPath("[.b,", ".a"))
+	assert.Equal(t, "[.a", insertPath("[", ".a"))
+	assert.Equal(t, "f(.b; .a", insertPath("f(.b;", ".a"))
+	assert.Equal(t, "select(.b == .a", insertPath("select(.b ==", ".a"))
+	assert.Equal(t, "if .a", insertPath("if", ".a"))
+	assert.Equal(t, ".b and .a", insertPath(".b and ", ".a"))
+
+	// A trailing comment is ended first
+	assert.Equal(t, ".b # c\n| .a", insertPath(".b # c", ".a"))
+	assert.Equal(t, ".b | # c\n.a", insertPath(".b | # c\n", ".a"))
+}
+
+func TestValueAt(t *testing.T) {
+	values := []any{
+		orderedObject{{key: "items", value: []any{"a", orderedObject{{key: "b c", value: nil}}}}},
+		json.Number("2"),
+	}
+
+	v, ok := valueAt(values, []any{0, "items", 1, "b c"})
+	assert.True(t, ok)
+	assert.Nil(t, v)
+
+	v, ok = valueAt(values, []any{1})
+	assert.True(t, ok)
+	assert.Equal(t, json.Number("2"), v)
+
+	for _, path := range [][]any{{2}, {0, "missing"}, {0, "items", 2}, {0, "items", "a"}, {1, 0}} {
+		_, ok = valueAt(values, path)
+		assert.False(t, ok, "%v", path)
+	}
+}
@@ -39,7 +39,8 @@ func TestAppRace(t *testing.T) {
 
 	// Run the app on a simulation screen so we can deterministically inject key
 	// events without requiring a real terminal.
-	app := createApp(doc)
+	var status int
+	app := createApp(doc, &status)
 	screen := tcell.NewSimulationScreen("")
 	if err := screen.Init(); err != nil {
 		t.Fatalf("init simulation screen: %v", err)
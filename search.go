@@ -0,0 +1,386 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// matchRegion is the prefix of the IDs of the regions which surround search
+// matches in a pane.
+const matchRegion = "match-"
+
+// compileSearch compiles a search pattern entered in a pane. Patterns are
+// regular expressions which ignore case unless they contain an upper case
+// letter. A pattern which is not a valid regular expression is matched
+// literally.
+func compileSearch(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+
+	flags := ""
+	if strings.ToLower(pattern) == pattern {
+		flags = "(?i)"
+	}
+
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		re = regexp.MustCompile(flags + regexp.QuoteMeta(pattern))
+	}
+
+	return re
+}
+
+// Search highlights the matches of re in the text of the pane and selects the
+// first match at or below the line row, or at or above it if backward is set.
+// A nil re clears the search.
+//
+// Regions and highlights of a text view are not safe for concurrent use, so
+// the methods which change them must only be called from the application's
+// event loop. The text of the pane can still be written from any goroutine.
+func (p *pane) Search(re *regexp.Regexp, backward bool, row int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if re == nil {
+		if p.highlighted {
+			p.restore()
+		}
+
+		p.tv.Highlight()
+		p.tv.SetRegions(false)
+		p.search = nil
+		p.matches = nil
+		return
+	}
+
+	if !p.highlighted {
+		p.raw = p.tv.GetText(false)
+	}
+
+	p.search = re
+	p.backward = backward
+	p.highlight()
+
+	p.current = -1
+	for i, line := range p.matches {
+		if backward && line <= row {
+			p.current = i
+		} else if !backward && line >= row {
+			p.current = i
+			break
+		}
+	}
+
+	if p.current == -1 && len(p.matches) > 0 {
+		// Wrap around to the other end of the pane
+		p.current = 0
+		if backward {
+			p.current = len(p.matches) - 1
+		}
+	}
+
+	p.selectMatch(true)
+}
+
+// NextMatch selects the next match in the direction of the search, or in the
+// opposite direction if reverse is set.
+func (p *pane) NextMatch(reverse bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.matches)
+	if n == 0 {
+		return
+	}
+
+	delta := 1
+	if p.backward != reverse {
+		delta = -1
+	}
+
+	p.current = ((p.current+delta)%n + n) % n
+	p.selectMatch(true)
+}
+
+// MatchStatus describes the selected match for the title of the pane. It
+// returns an empty string if there is no active search.
+func (p *pane) MatchStatus() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.search == nil {
+		return ""
+	}
+
+	if len(p.matches) == 0 {
+		return "no matches"
+	}
+
+	return fmt.Sprintf("match %d of %d", p.current+1, len(p.matches))
+}
+
+// refreshSearch highlights the matches of the active search again after the
+// contents of the pane have changed.
+func (p *pane) refreshSearch() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.search == nil {
+		return
+	}
+
+	if !p.highlighted {
+		p.raw = p.tv.GetText(false)
+	}
+
+	p.highlight()
+	p.current = min(max(p.current, 0), len(p.matches)-1)
+	p.selectMatch(false)
+}
+
+// highlight replaces the text of the pane with the raw text with every match
+// of the search surrounded by a region. p.mu must be held.
+func (p *pane) highlight() {
+	text, matches := highlightMatches(p.raw, p.search)
+	p.matches = matches
+	p.tv.SetRegions(true)
+	p.tv.SetText(text)
+	p.highlighted = true
+}
+
+// restore replaces the highlighted text of the pane with the raw text. p.mu
+// must be held.
+func (p *pane) restore() {
+	p.tv.SetText(p.raw)
+	p.raw = ""
+	p.highlighted = false
+}
+
+// selectMatch highlights the current match and, if scroll is set, scrolls the
+// pane to show it. p.mu must be held.
+func (p *pane) selectMatch(scroll bool) {
+	if p.current < 0 {
+		p.tv.Highlight()
+		return
+	}
+
+	p.tv.Highlight(fmt.Sprintf("%s%d", matchRegion, p.current))
+	if scroll {
+		p.tv.ScrollToHighlight()
+	}
+}
+
+// highlightMatches surrounds each match of re in text, which may contain
+// style tags, with a region and underlines it. Matches do not span lines. It
+// returns the highlighted text and the line of each match.
+func highlightMatches(text string, re *regexp.Regexp) (string, []int) {
+	var (
+		b     strings.Builder
+		lines []int
+	)
+
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		tokens := splitTags(line)
+
+		var plain strings.Builder
+		for _, t := range tokens {
+			if !t.tag {
+				plain.WriteString(t.text)
+			}
+		}
+
+		var matches [][]int
+		for _, m := range re.FindAllStringIndex(plain.String(), -1) {
+			if m[1] > m[0] {
+				matches = append(matches, m)
+			}
+		}
+
+		// Offset of the current position in the plain text, the index
+		// of the next match and whether the position is inside it
+		pos, next, inMatch := 0, 0, false
+		for _, t := range tokens {
+			if t.tag {
+				b.WriteString(t.text)
+				if inMatch {
+					// The tag may have reset the attributes
+					b.WriteString("[::u]")
+				}
+
+				continue
+			}
+
+			text := t.text
+			for len(text) > 0 {
+				boundary := pos + len(text)
+				if inMatch {
+					boundary = matches[next][1]
+				} else if next < len(matches) {
+					boundary = matches[next][0]
+				}
+
+				n := min(boundary-pos, len(text))
+				b.WriteString(tview.Escape(text[:n]))
+				text = text[n:]
+				pos += n
+
+				if pos != boundary {
+					continue
+				}
+
+				if inMatch {
+					b.WriteString(`[::U][""]`)
+					inMatch = false
+					next++
+				} else if next < len(matches) {
+					fmt.Fprintf(&b, `["%s%d"][::u]`, matchRegion, len(lines))
+					lines = append(lines, i)
+					inMatch = true
+				}
+			}
+		}
+	}
+
+	return b.String(), lines
+}
+
+// tagToken is either a style tag or plain text in a line of text shown in a
+// pane.
+type tagToken struct {
+	text string
+	tag  bool
+}
+
+// escapedTag matches the escaped form of text which looks like a tag, as
+// returned by tview.Escape.
+var escapedTag = regexp.MustCompile(`^\[[^\[\]]+\[+\]`)
+
+// splitTags splits a line of text into style tags and plain text. Escaped
+// tags are returned as the plain text they stand for.
+func splitTags(line string) []tagToken {
+	var (
+		tokens []tagToken
+		plain  strings.Builder
+	)
+
+	flush := func() {
+		if plain.Len() > 0 {
+			tokens = append(tokens, tagToken{text: plain.String()})
+			plain.Reset()
+		}
+	}
+
+	for len(line) > 0 {
+		i := strings.IndexByte(line, '[')
+		if i == -1 {
+			plain.WriteString(line)
+			break
+		}
+
+		plain.WriteString(line[:i])
+		line = line[i:]
+
+		if n := styleTagLength(line); n > 0 {
+			flush()
+			tokens = append(tokens, tagToken{text: line[:n], tag: true})
+			line = line[n:]
+			continue
+		}
+
+		if m := escapedTag.FindString(line); m != "" {
+			// The last opening bracket only escapes the tag
+			plain.WriteString(m[:len(m)-2] + "]")
+			line = line[len(m):]
+			continue
+		}
+
+		plain.WriteByte('[')
+		line = line[1:]
+	}
+
+	flush()
+	return tokens
+}
+
+// styleTagLength returns the length of the style tag at the start of s, or 0
+// if s does not start with one. It follows the rules tview uses to parse
+// style tags of the form [foreground:background:attributes:url].
+func styleTagLength(s string) int {
+	if len(s) < 2 || s[0] != '[' {
+		return 0
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end == -1 {
+		return 0
+	}
+
+	fields := strings.SplitN(s[1:end], ":", 4)
+	if len(fields) == 1 && fields[0] == "" {
+		return 0
+	}
+
+	for i, field := range fields {
+		switch i {
+		case 0, 1:
+			if !isTagColor(field) {
+				return 0
+			}
+		case 2:
+			if field != "-" && strings.Trim(field, "buildsrBUILDSR") != "" {
+				return 0
+			}
+		}
+	}
+
+	return end + 1
+}
+
+// isTagColor reports whether s is a valid color in a style tag.
+func isTagColor(s string) bool {
+	switch {
+	case s == "" || s == "-":
+		return true
+	case s[0] == '#':
+		if len(s) != 7 {
+			return false
+		}
+
+		return strings.Trim(s[1:], "0123456789abcdefABCDEF") == ""
+	case s[0] >= '0' && s[0] <= '9':
+		return false
+	}
+
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
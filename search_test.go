@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSearch(t *testing.T) {
+	assert.Nil(t, compileSearch(""))
+
+	// Lower case patterns ignore case
+	assert.True(t, compileSearch("key").MatchString("KEY"))
+	assert.False(t, compileSearch("Key").MatchString("KEY"))
+
+	assert.True(t, compileSearch(`k.y`).MatchString("kay"))
+
+	// Invalid regular expressions are matched literally
+	re := compileSearch("a[")
+	require.NotNil(t, re)
+	assert.True(t, re.MatchString("a["))
+}
+
+func TestSplitTags(t *testing.T) {
+	tokens := splitTags(`[#ff0000:-:b]"a"[-:-:-]: [1] [x[] [::u]`)
+	assert.Equal(t, []tagToken{
+		{text: "[#ff0000:-:b]", tag: true},
+		{text: `"a"`},
+		{text: "[-:-:-]", tag: true},
+		{text: ": [1] [x] "},
+		{text: "[::u]", tag: true},
+	}, tokens)
+}
+
+func TestHighlightMatches(t *testing.T) {
+	text, lines := highlightMatches("[red]\"key\"[-:-:-]: 1\nnone\nkey key", compileSearch("key"))
+	assert.Equal(t, []int{0, 2, 2}, lines)
+	assert.Equal(t, `[red]"["match-0"][::u]key[::U][""]"[-:-:-]: 1`+"\n"+
+		"none\n"+
+		`["match-1"][::u]key[::U][""] ["match-2"][::u]key[::U][""]`, text)
+
+	// Matches spanning tags keep their underline, and literal text is
+	// escaped so that it is not mistaken for a tag
+	text, lines = highlightMatches(`a[red]b["c"]`, compileSearch("ab"))
+	assert.Equal(t, []int{0}, lines)
+	assert.Equal(t, `["match-0"][::u]a[red][::u]b[::U][""]["c"[]`, text)
+
+	tv := tview.NewTextView().SetDynamicColors(true).SetRegions(true).SetText(text)
+	assert.Equal(t, `ab["c"]`, tv.GetText(true))
+
+	_, lines = highlightMatches("abc", compileSearch("x*"))
+	assert.Empty(t, lines)
+}
+
+func TestPaneSearch(t *testing.T) {
+	p := &pane{tv: tview.NewTextView().SetDynamicColors(true)}
+	_, err := p.Write([]byte("one\ntwo\none\nthree\none\n"))
+	require.NoError(t, err)
+	assert.Empty(t, p.MatchStatus())
+
+	p.Search(compileSearch("one"), false, 1)
+	assert.Equal(t, "match 2 of 3", p.MatchStatus())
+	assert.Equal(t, []string{"match-1"}, p.tv.GetHighlights())
+	assert.Equal(t, "one\ntwo\none\nthree\none\n", p.tv.GetText(true))
+
+	p.NextMatch(false)
+	assert.Equal(t, "match 3 of 3", p.MatchStatus())
+	p.NextMatch(false)
+	assert.Equal(t, "match 1 of 3", p.MatchStatus())
+	p.NextMatch(true)
+	assert.Equal(t, "match 3 of 3", p.MatchStatus())
+
+	// Backward searches start above the line and wrap around
+	p.Search(compileSearch("one"), true, 1)
+	assert.Equal(t, "match 1 of 3", p.MatchStatus())
+	p.NextMatch(false)
+	assert.Equal(t, "match 3 of 3", p.MatchStatus())
+
+	p.Search(compileSearch("four"), false, 0)
+	assert.Equal(t, "no matches", p.MatchStatus())
+
+	// The search is applied again when the pane is rewritten
+	p.Search(compileSearch("t"), false, 0)
+	p.dirty = true
+	_, err = p.Write([]byte("tt\n"))
+	require.NoError(t, err)
+	p.refreshSearch()
+	assert.Equal(t, "match 1 of 2", p.MatchStatus())
+	assert.Equal(t, "tt\n", p.tv.GetText(true))
+
+	p.Search(nil, false, 0)
+	assert.Empty(t, p.MatchStatus())
+	assert.Equal(t, "tt\n", p.tv.GetText(false))
+}
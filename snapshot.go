@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"codeberg.org/gpanders/ijq/internal/overlay"
+)
+
+// snapshot is a filter and its output, pinned so that the output of other
+// filters can be compared with it.
+type snapshot struct {
+	name   string
+	filter string
+	output string
+	lines  int
+}
+
+// snapshotPane shows a pinned snapshot in a third pane next to the output
+// pane. Snapshots are only held for the session.
+type snapshotPane struct {
+	view      *tview.TextView
+	snapshots []snapshot
+
+	// The index of the snapshot shown in the pane, or -1, and whether the
+	// pane is visible. The snapshot is kept when the pane is hidden so that
+	// it can be shown again.
+	shown   int
+	visible bool
+
+	// The number of snapshots pinned so far, used to name snapshots which
+	// are pinned without a name
+	pinned int
+}
+
+func newSnapshotPane() *snapshotPane {
+	s := &snapshotPane{view: tview.NewTextView(), shown: -1}
+	s.view.SetDynamicColors(true).SetWrap(false).SetBorder(true)
+	return s
+}
+
+// Pin adds a snapshot of filter and its output, which holds the style tags of
+// the output pane, and returns its index. An empty name is replaced by a
+// numbered one.
+func (s *snapshotPane) Pin(name, filter, output string) int {
+	s.pinned++
+	if name == "" {
+		name = fmt.Sprintf("snapshot %d", s.pinned)
+	}
+
+	s.snapshots = append(s.snapshots, snapshot{
+		name:   name,
+		filter: filter,
+		output: output,
+		lines:  strings.Count(output, "\n"),
+	})
+
+	return len(s.snapshots) - 1
+}
+
+// Show shows the snapshot at index in the pane.
+func (s *snapshotPane) Show(index int) {
+	if index < 0 || index >= len(s.snapshots) {
+		return
+	}
+
+	s.shown, s.visible = index, true
+	s.view.SetText(s.snapshots[index].output)
+	s.view.ScrollToBeginning()
+}
+
+// Toggle hides the pane if it is visible, or otherwise shows the snapshot it
+// showed last or, failing that, the snapshot pinned last.
+func (s *snapshotPane) Toggle() {
+	switch {
+	case s.visible:
+		s.visible = false
+	case s.shown >= 0:
+		s.visible = true
+	default:
+		s.Show(len(s.snapshots) - 1)
+	}
+}
+
+// Delete removes the snapshot at index, hiding the pane if it shows it.
+func (s *snapshotPane) Delete(index int) error {
+	if index < 0 || index >= len(s.snapshots) {
+		return fmt.Errorf("no snapshot at index %d", index)
+	}
+
+	s.snapshots = append(s.snapshots[:index], s.snapshots[index+1:]...)
+	switch {
+	case index == s.shown:
+		s.shown, s.visible = -1, false
+		s.view.Clear()
+	case index < s.shown:
+		s.shown--
+	}
+
+	return nil
+}
+
+// Entries returns the snapshots as listed in the overlay.
+func (s *snapshotPane) Entries() []overlay.SnapshotEntry {
+	entries := make([]overlay.SnapshotEntry, len(s.snapshots))
+	for i, snap := range s.snapshots {
+		entries[i] = overlay.SnapshotEntry{Name: snap.name, Filter: snap.filter}
+	}
+
+	return entries
+}
+
+// updateTitle sets the title of the pane to the name of the snapshot it
+// shows, the scroll position and its filter.
+func (s *snapshotPane) updateTitle() {
+	if s.shown < 0 {
+		return
+	}
+
+	snap := s.snapshots[s.shown]
+	updateScrollIndicator(tview.Escape(snap.name), snap.lines, s.view, tview.Escape(strings.Join(strings.Fields(snap.filter), " ")))
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codeberg.org/gpanders/ijq/internal/overlay"
+)
+
+func TestSnapshotPane(t *testing.T) {
+	s := newSnapshotPane()
+
+	// The pane shows the snapshot pinned last when there is nothing else
+	// to show
+	s.Toggle()
+	assert.False(t, s.visible)
+
+	assert.Equal(t, 0, s.Pin("", ".a", "1\n"))
+	assert.Equal(t, 1, s.Pin("names", ".b", "[blue]\"x\"[-]\n"))
+	assert.Equal(t, 2, s.Pin("", ".c", "null\n"))
+	assert.Equal(t, []overlay.SnapshotEntry{
+		{Name: "snapshot 1", Filter: ".a"},
+		{Name: "names", Filter: ".b"},
+		{Name: "snapshot 3", Filter: ".c"},
+	}, s.Entries())
+
+	s.Toggle()
+	assert.True(t, s.visible)
+	assert.Equal(t, 2, s.shown)
+	assert.Equal(t, "null\n", s.view.GetText(false))
+
+	s.Show(1)
+	assert.Equal(t, `"x"`+"\n", s.view.GetText(true))
+	assert.Equal(t, 1, s.snapshots[s.shown].lines)
+
+	// The shown snapshot is kept when the pane is hidden and when other
+	// snapshots are deleted
+	s.Toggle()
+	assert.False(t, s.visible)
+	assert.NoError(t, s.Delete(0))
+	assert.Equal(t, 0, s.shown)
+
+	s.Toggle()
+	assert.True(t, s.visible)
+	assert.Equal(t, "names", s.snapshots[s.shown].name)
+
+	// Deleting the shown snapshot hides the pane
+	assert.NoError(t, s.Delete(0))
+	assert.False(t, s.visible)
+	assert.Equal(t, -1, s.shown)
+
+	assert.Error(t, s.Delete(1))
+
+	// Numbered names keep counting after snapshots are deleted
+	s.Pin("", ".d", "")
+	assert.Equal(t, "snapshot 4", s.Entries()[1].Name)
+}
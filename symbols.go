@@ -0,0 +1,420 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"cmp"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/rivo/tview"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+type symbolKind int
+
+const (
+	symbolBuiltin   symbolKind = iota // length, $ENV
+	symbolFunction                    // def f: ...;
+	symbolParameter                   // def f(g; $x): ...;
+	symbolVariable                    // ... as $x
+	symbolArgument                    // -arg x value
+	symbolModule                      // import "x" as x;
+)
+
+func (k symbolKind) String() string {
+	switch k {
+	case symbolBuiltin:
+		return "builtin"
+	case symbolFunction:
+		return "function"
+	case symbolParameter:
+		return "parameter"
+	case symbolVariable:
+		return "variable"
+	case symbolArgument:
+		return "argument"
+	case symbolModule:
+		return "module"
+	}
+
+	return ""
+}
+
+// symbol is a variable, function or module which can be completed in a
+// filter.
+type symbol struct {
+	// name is the text inserted by a completion: the name of a function,
+	// the name of a variable without its $, or the quoted path of a module
+	name     string
+	kind     symbolKind
+	variable bool
+
+	// signature is shown in the hint of the symbol, e.g. f(a; $b), and
+	// description, when set, is shown in place of its kind
+	signature   string
+	description string
+
+	// positions are the byte offsets of the characters of name which
+	// match the word being completed
+	positions []int
+}
+
+// builtinVariables are the variables jq defines for every filter.
+var builtinVariables = []symbol{
+	{name: "ENV", kind: symbolBuiltin, variable: true, signature: "$ENV", description: "An object holding the environment variables."},
+	{name: "ARGS", kind: symbolBuiltin, variable: true, signature: "$ARGS", description: "The named and positional arguments."},
+	{name: "__loc__", kind: symbolBuiltin, variable: true, signature: "$__loc__", description: "The file and line number where it occurs."},
+}
+
+// symbol returns the symbol which completes b.
+func (b builtin) symbol() symbol {
+	return symbol{name: b.name, kind: symbolBuiltin, signature: b.Signature(), description: b.description}
+}
+
+// variableSymbol returns the symbol of the variable name, which starts with
+// a $.
+func variableSymbol(name string, kind symbolKind) symbol {
+	return symbol{name: strings.TrimPrefix(name, "$"), kind: kind, variable: true, signature: name}
+}
+
+// defSymbols returns the symbols defined by def name(params): the function
+// itself, followed by its parameters. A parameter $p defines both the
+// variable $p and the function p.
+func defSymbols(name string, params []string) []symbol {
+	signature := name
+	if len(params) > 0 {
+		signature += "(" + strings.Join(params, "; ") + ")"
+	}
+
+	symbols := []symbol{{name: name, kind: symbolFunction, signature: signature}}
+	for _, param := range params {
+		if strings.HasPrefix(param, "$") {
+			symbols = append(symbols, variableSymbol(param, symbolParameter))
+		}
+
+		param = strings.TrimPrefix(param, "$")
+		symbols = append(symbols, symbol{name: param, kind: symbolParameter, signature: param})
+	}
+
+	return symbols
+}
+
+// symbolEntries returns the autocomplete entries for symbols, which replace
+// the word being typed between before and after, the hint shown for each
+// entry and the offsets of its characters which match the word. Symbols with
+// the same name, such as builtins which take different numbers of arguments,
+// share an entry whose hint lists each signature.
+func symbolEntries(before, after string, symbols []symbol) (entries, hints []string, highlights [][]int) {
+	var names []string
+	groups := make(map[string][]symbol)
+	for _, s := range symbols {
+		if _, ok := groups[s.name]; !ok {
+			names = append(names, s.name)
+		}
+
+		groups[s.name] = append(groups[s.name], s)
+	}
+
+	for _, name := range names {
+		group := groups[name]
+
+		var signatures []string
+		description := ""
+		for _, s := range group {
+			if signature := cmp.Or(s.signature, s.name); !slices.Contains(signatures, signature) {
+				signatures = append(signatures, signature)
+			}
+
+			if s.kind == group[0].kind {
+				description = cmp.Or(description, s.description)
+			}
+		}
+
+		hint := "[::b]" + tview.Escape(strings.Join(signatures, ", ")) + "[::-]  " + tview.Escape(cmp.Or(description, group[0].kind.String()))
+		offsets := make([]int, len(group[0].positions))
+		for i, pos := range group[0].positions {
+			offsets[i] = len(before) + pos
+		}
+
+		entries = append(entries, before+name+after)
+		hints = append(hints, hint)
+		highlights = append(highlights, offsets)
+	}
+
+	return entries, hints, highlights
+}
+
+// rankSymbols returns the symbols whose names match word, best first, with
+// the characters which match it.
+func rankSymbols(symbols []symbol, word string, mode AutocompleteMatch) []symbol {
+	names := make([]string, len(symbols))
+	for i, s := range symbols {
+		names[i] = s.name
+	}
+
+	var ranked []symbol
+	for _, m := range mode.matchAll(names, word, nil) {
+		s := symbols[m.index]
+		s.positions = m.positions
+		ranked = append(ranked, s)
+	}
+
+	return ranked
+}
+
+// variableCandidates returns the variables which complete the word typed in
+// c: those bound in the filter, the data of the modules it imports, the named
+// arguments and the builtin variables.
+func variableCandidates(c completionContext, opts options.Options, mode AutocompleteMatch) []symbol {
+	var symbols []symbol
+	for _, s := range c.symbols {
+		if s.variable {
+			symbols = append(symbols, s)
+		}
+	}
+
+	for _, m := range c.imports {
+		// The data of a module imported as $name is bound to
+		// $name::name
+		if name, ok := strings.CutPrefix(m.alias, "$"); ok && m.path != "" {
+			s := variableSymbol(m.alias+"::"+name, symbolModule)
+			s.description = "data of module " + quoteJSON(m.path)
+			symbols = append(symbols, s)
+		}
+	}
+
+	for _, name := range variableNames(opts) {
+		if i := slices.IndexFunc(builtinVariables, func(s symbol) bool { return s.name == name }); i >= 0 {
+			symbols = append(symbols, builtinVariables[i])
+		} else {
+			symbols = append(symbols, variableSymbol("$"+name, symbolArgument))
+		}
+	}
+
+	return rankSymbols(symbols, c.word, mode)
+}
+
+// functionCandidates returns the functions which complete the word typed in
+// c: those defined in the filter, those of the modules it includes, the
+// modules it imports, followed by their functions once mod:: is typed, and
+// builtins.
+func functionCandidates(c completionContext, opts options.Options, builtins []builtin, mode AutocompleteMatch) []symbol {
+	var symbols []symbol
+	for _, s := range c.symbols {
+		if !s.variable {
+			symbols = append(symbols, s)
+		}
+	}
+
+	paths := libraryPaths(opts)
+	qualified := strings.Contains(c.word, "::")
+	for _, m := range c.imports {
+		if m.path == "" || strings.HasPrefix(m.alias, "$") {
+			continue
+		}
+
+		switch {
+		case m.include:
+			symbols = append(symbols, moduleFunctions(paths, m.path, "")...)
+		case m.alias != "" && qualified:
+			symbols = append(symbols, moduleFunctions(paths, m.path, m.alias+"::")...)
+		case m.alias != "":
+			symbols = append(symbols, symbol{name: m.alias + "::", kind: symbolModule, signature: m.alias, description: "module " + quoteJSON(m.path)})
+		}
+	}
+
+	for _, b := range builtins {
+		symbols = append(symbols, b.symbol())
+	}
+
+	return rankSymbols(symbols, c.word, mode)
+}
+
+// moduleCandidates returns the modules on the library paths which complete the
+// path typed in c. Their paths are matched without the quotes around them.
+func moduleCandidates(c completionContext, opts options.Options, mode AutocompleteMatch) []symbol {
+	modules := findModules(libraryPaths(opts))
+	paths := make([]string, len(modules))
+	for i, m := range modules {
+		paths[i] = m.signature
+	}
+
+	var symbols []symbol
+	for _, match := range mode.matchAll(paths, c.word, nil) {
+		m := modules[match.index]
+		for _, pos := range match.positions {
+			m.positions = append(m.positions, len(quoteJSON(m.signature[:pos]))-1)
+		}
+
+		symbols = append(symbols, m)
+	}
+
+	return symbols
+}
+
+// libraryPaths returns the directories modules are searched in, with ~
+// expanded as jq does.
+func libraryPaths(opts options.Options) []string {
+	var paths []string
+	for _, p := range opts.LibraryPaths {
+		if rest, ok := strings.CutPrefix(p, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+
+			p = filepath.Join(home, rest)
+		}
+
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
+// lookupModule returns the file of the module name with extension ext, which
+// is either name.ext or name/base.ext in one of paths, where base is the last
+// element of name. It returns an empty string if there is no such file.
+func lookupModule(paths []string, name, ext string) string {
+	for _, base := range paths {
+		for _, file := range []string{
+			filepath.Join(base, name+ext),
+			filepath.Join(base, name, path.Base(name)+ext),
+		} {
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file
+			}
+		}
+	}
+
+	return ""
+}
+
+// moduleFunctions returns the functions defined by the module name, including
+// those of the modules it includes, with prefix added to their names.
+func moduleFunctions(paths []string, name, prefix string) []symbol {
+	return appendModuleFunctions(nil, paths, name, prefix, make(map[string]bool))
+}
+
+func appendModuleFunctions(symbols []symbol, paths []string, name, prefix string, seen map[string]bool) []symbol {
+	file := lookupModule(paths, name, ".jq")
+	if file == "" || seen[file] {
+		return symbols
+	}
+
+	seen[file] = true
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return symbols
+	}
+
+	query, err := gojq.Parse(string(contents))
+	if err != nil {
+		return symbols
+	}
+
+	for _, def := range query.FuncDefs {
+		if strings.HasPrefix(def.Name, "_") {
+			continue
+		}
+
+		s := defSymbols(prefix+def.Name, def.Args)[0]
+		s.description = "function of module " + quoteJSON(name)
+		symbols = append(symbols, s)
+	}
+
+	for _, i := range query.Imports {
+		if i.IncludePath != "" {
+			symbols = appendModuleFunctions(symbols, paths, i.IncludePath, prefix, seen)
+		}
+	}
+
+	return symbols
+}
+
+// findModules returns the modules in the library paths, as the quoted names
+// they are imported by. Directories are searched at most three levels deep
+// and at most 1000 modules are returned.
+func findModules(paths []string) []symbol {
+	const maxDepth, maxModules = 3, 1000
+
+	var modules []symbol
+	for _, base := range paths {
+		if len(modules) >= maxModules {
+			break
+		}
+
+		_ = filepath.WalkDir(base, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			rel, err := filepath.Rel(base, file)
+			if err != nil {
+				return nil
+			}
+
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				if rel != "." && (strings.HasPrefix(d.Name(), ".") || strings.Count(rel, "/") >= maxDepth-1) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			ext := path.Ext(rel)
+			if ext != ".jq" && ext != ".json" {
+				return nil
+			}
+
+			// name/name.jq is imported as name
+			name := strings.TrimSuffix(rel, ext)
+			if dir, file := path.Split(name); dir != "" && path.Base(dir) == file {
+				name = strings.TrimSuffix(dir, "/")
+			}
+
+			description := ""
+			if ext == ".json" {
+				description = "data module"
+			}
+
+			modules = append(modules, symbol{name: quoteJSON(name), kind: symbolModule, signature: name, description: description})
+			if len(modules) >= maxModules {
+				return filepath.SkipAll
+			}
+
+			return nil
+		})
+	}
+
+	slices.SortStableFunc(modules, func(a, b symbol) int {
+		return strings.Compare(a.signature, b.signature)
+	})
+
+	return modules
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func TestSymbolEntries(t *testing.T) {
+	builtins := parseBuiltins([]string{"splits/1", "splits/2", "sort/0", "sortof/0"})
+
+	var symbols []symbol
+	symbols = append(symbols, defSymbols("sort", []string{"f"})[0])
+	for _, b := range builtins {
+		symbols = append(symbols, b.symbol())
+	}
+
+	entries, hints, highlights := symbolEntries(".[] | ", " | length", rankSymbols(symbols, "so", matchPrefix))
+	assert.Equal(t, []string{".[] | sort | length", ".[] | sortof | length"}, entries)
+	assert.Equal(t, [][]int{{6, 7}, {6, 7}}, highlights)
+
+	entries, hints, _ = symbolEntries(".[] | ", " | length", symbols)
+	assert.Equal(t, []string{".[] | sort | length", ".[] | sortof | length", ".[] | splits | length"}, entries)
+	assert.Equal(t, []string{
+		"[::b]sort(f), sort[::-]  function",
+		"[::b]sortof/0[::-]  builtin",
+		"[::b]splits(re), splits(re; flags)[::-]  Outputs the parts of a string split on matches of re.",
+	}, hints)
+}
+
+func TestDefSymbols(t *testing.T) {
+	assert.Equal(t, []symbol{
+		{name: "f", kind: symbolFunction, signature: "f(g; $x)"},
+		{name: "g", kind: symbolParameter, signature: "g"},
+		{name: "x", kind: symbolParameter, variable: true, signature: "$x"},
+		{name: "x", kind: symbolParameter, signature: "x"},
+	}, defSymbols("f", []string{"g", "$x"}))
+
+	assert.Equal(t, []symbol{{name: "f", kind: symbolFunction, signature: "f"}}, defSymbols("f", nil))
+}
+
+func TestVariableCandidates(t *testing.T) {
+	opts := options.Options{StringArgs: options.StringArgs{{Name: "name", Value: "x"}}}
+
+	filter := `import "data" as $data; . as $n | $`
+	var names []string
+	for _, s := range variableCandidates(completionContextAt(filter, len(filter)), opts, matchPrefix) {
+		names = append(names, s.signature)
+	}
+	assert.Equal(t, []string{"$n", "$data::data", "$ENV", "$ARGS", "$__loc__", "$name"}, names)
+
+	filter = ". as $n | $na"
+	entries, hints, _ := symbolEntries("", "", variableCandidates(completionContextAt(filter, len(filter)), opts, matchPrefix))
+	assert.Equal(t, []string{"name"}, entries)
+	assert.Equal(t, []string{"[::b]$name[::-]  argument"}, hints)
+
+	filter = ". as $n | $rg"
+	entries, _, _ = symbolEntries("", "", variableCandidates(completionContextAt(filter, len(filter)), opts, matchFuzzy))
+	assert.Equal(t, []string{"ARGS"}, entries)
+}
+
+func writeModules(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"util.jq":            `include "extra"; def double: . * 2; def _private: .; def pad($n; s): .;`,
+		"extra.jq":           `def triple: . * 3;`,
+		"lib/lib.jq":         `def helper: .;`,
+		"lib/other.jq":       `def other: .;`,
+		"data.json":          `{"a": 1}`,
+		".hidden/skipped.jq": `def skipped: .;`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+
+	return dir
+}
+
+func TestFunctionCandidates(t *testing.T) {
+	opts := options.Options{LibraryPaths: []string{writeModules(t)}}
+	builtins := parseBuiltins([]string{"path/1", "pick/1"})
+
+	complete := func(filter string, mode AutocompleteMatch) []string {
+		c := completionContextAt(filter, len(filter))
+		require.Equal(t, completeFunction, c.kind, filter)
+
+		var names []string
+		for _, s := range functionCandidates(c, opts, builtins, mode) {
+			names = append(names, s.name)
+		}
+
+		return names
+	}
+
+	// Functions of the filter come first, then those of included modules
+	assert.Equal(t, []string{"pad", "pad", "path", "pick"}, complete(`include "util"; def pad: .; p`, matchPrefix))
+	assert.Equal(t, []string{"triple"}, complete(`include "util"; . | t`, matchPrefix))
+	assert.Equal(t, []string{"util::"}, complete(`import "util" as util; u`, matchPrefix))
+	assert.Equal(t, []string{"util::pad", "util::triple"}, complete(`import "util" as util; util::`, matchPrefix)[1:])
+	assert.Equal(t, []string{"lib::helper"}, complete(`import "lib" as lib; lib::h`, matchPrefix))
+
+	// Fuzzy matches rank those starting words first
+	assert.Equal(t, []string{"util::pad", "util::triple"}, complete(`import "util" as util; util::`, matchFuzzy)[1:])
+	assert.Equal(t, []string{"util::double", "util::pad"}, complete(`import "util" as util; u::d`, matchFuzzy))
+
+	// Unknown modules have no functions
+	assert.Empty(t, complete(`import "missing" as m; m::`, matchPrefix))
+}
+
+func TestKeysFilterModules(t *testing.T) {
+	opts := options.Options{Engine: "gojq", LibraryPaths: []string{writeModules(t)}}
+
+	// The modules the filter imports are kept when looking up keys
+	for filter, want := range map[string]map[string]int{
+		`import "data" as $data; $data::data[].`:          {"a": 1},
+		`import "util" as util; .b | util::pad(1; .) | .`: {"c": 1},
+		`include "util"; . as $x | .b | pad(1; $x) | .`:   {"c": 1},
+	} {
+		c := completionContextAt(filter, len(filter))
+		require.Equal(t, completeKey, c.kind, filter)
+
+		doc := Document{
+			input:     `{"b":{"c":1}}`,
+			filter:    keysFilter(c.directives, c.input),
+			options:   opts,
+			evaluator: newEvaluator(opts),
+			ctx:       context.Background(),
+		}
+
+		var buf bytes.Buffer
+		_, err := doc.WriteTo(&buf)
+		require.NoError(t, err, filter)
+
+		keys, err := decodeKeys(buf.Bytes())
+		require.NoError(t, err, filter)
+		assert.Equal(t, want, keys, filter)
+	}
+}
+
+func TestModuleCandidates(t *testing.T) {
+	opts := options.Options{LibraryPaths: []string{writeModules(t)}}
+
+	filter := `import "`
+	c := completionContextAt(filter, len(filter))
+	require.Equal(t, completeModule, c.kind)
+
+	entries, hints, _ := symbolEntries(filter[:c.start], "", moduleCandidates(c, opts, matchPrefix))
+	assert.Equal(t, []string{`import "data"`, `import "extra"`, `import "lib"`, `import "lib/other"`, `import "util"`}, entries)
+	assert.Equal(t, "[::b]data[::-]  data module", hints[0])
+	assert.Equal(t, "[::b]lib[::-]  module", hints[2])
+
+	filter = `include "li`
+	c = completionContextAt(filter, len(filter))
+	entries, _, highlights := symbolEntries(filter[:c.start], "", moduleCandidates(c, opts, matchPrefix))
+	assert.Equal(t, []string{`include "lib"`, `include "lib/other"`}, entries)
+	assert.Equal(t, [][]int{{9, 10}, {9, 10}}, highlights)
+
+	filter = `include "oth`
+	c = completionContextAt(filter, len(filter))
+	entries, _, _ = symbolEntries(filter[:c.start], "", moduleCandidates(c, opts, matchSubstring))
+	assert.Equal(t, []string{`include "lib/other"`}, entries)
+}
@@ -0,0 +1,379 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableCellWidth is the maximum width of a cell in a table. Longer values
+// are truncated.
+const tableCellWidth = 40
+
+// objectTable shows JSON objects in a table with a column for each key. It
+// is shown in place of the text of the output pane.
+type objectTable struct {
+	view    *tview.Table
+	enabled atomic.Bool
+
+	// Every key of the objects, in the order they are first seen, and the
+	// members of each object
+	columns []string
+	rows    []map[string]any
+
+	// Columns are identified by their key, so that a table stays sorted
+	// and keeps its hidden columns when the values change
+	sortKey    string
+	sorted     bool
+	descending bool
+	hidden     map[string]bool
+
+	// Style tags for each kind of value, following jq's colors
+	colors jsonColors
+}
+
+func newObjectTable() *objectTable {
+	t := &objectTable{
+		view:   tview.NewTable(),
+		hidden: make(map[string]bool),
+		colors: jsonColorTags(),
+	}
+
+	t.view.
+		SetFixed(1, 0).
+		SetSelectable(true, true).
+		SetSelectedStyle(tcell.StyleDefault.Reverse(true)).
+		SetBorder(true)
+
+	return t
+}
+
+// Enabled reports whether the table is shown in place of the text of its
+// pane.
+func (t *objectTable) Enabled() bool {
+	return t.enabled.Load()
+}
+
+// SetValues replaces the values shown in the table. The values must either
+// be objects or a single array of objects, otherwise the table only shows a
+// message saying so.
+func (t *objectTable) SetValues(values []any) {
+	objects, ok := tableObjects(values)
+	if !ok {
+		t.columns, t.rows = nil, nil
+		t.view.Clear()
+		t.view.SetCell(0, 0, tview.NewTableCell("[::d]Output is not an array of objects[::D]").SetSelectable(false))
+		return
+	}
+
+	t.columns = nil
+	t.rows = make([]map[string]any, len(objects))
+	seen := make(map[string]bool)
+	for i, object := range objects {
+		t.rows[i] = make(map[string]any, len(object))
+		for _, entry := range object {
+			t.rows[i][entry.key] = entry.value
+			if !seen[entry.key] {
+				seen[entry.key] = true
+				t.columns = append(t.columns, entry.key)
+			}
+		}
+	}
+
+	t.render()
+}
+
+// tableObjects returns the objects to show in a table for values, which are
+// either objects or a single array of objects.
+func tableObjects(values []any) ([]orderedObject, bool) {
+	if len(values) == 1 {
+		if array, ok := values[0].([]any); ok {
+			values = array
+		}
+	}
+
+	objects := make([]orderedObject, len(values))
+	for i, v := range values {
+		object, ok := v.(orderedObject)
+		if !ok {
+			return nil, false
+		}
+
+		objects[i] = object
+	}
+
+	return objects, true
+}
+
+// visibleColumns returns the keys of the columns which are not hidden.
+func (t *objectTable) visibleColumns() []string {
+	var columns []string
+	for _, key := range t.columns {
+		if !t.hidden[key] {
+			columns = append(columns, key)
+		}
+	}
+
+	return columns
+}
+
+// selectedKey returns the key of the selected column.
+func (t *objectTable) selectedKey() (string, bool) {
+	_, column := t.view.GetSelection()
+	columns := t.visibleColumns()
+	if column < 0 || column >= len(columns) {
+		return "", false
+	}
+
+	return columns[column], true
+}
+
+// SortSelected sorts the rows by the selected column, or reverses the order
+// if they are already sorted by it.
+func (t *objectTable) SortSelected() {
+	key, ok := t.selectedKey()
+	if !ok {
+		return
+	}
+
+	if t.sorted && t.sortKey == key {
+		t.descending = !t.descending
+	} else {
+		t.sortKey, t.sorted, t.descending = key, true, false
+	}
+
+	t.render()
+}
+
+// HideSelected hides the selected column. The last visible column cannot be
+// hidden.
+func (t *objectTable) HideSelected() {
+	key, ok := t.selectedKey()
+	if !ok || len(t.visibleColumns()) <= 1 {
+		return
+	}
+
+	t.hidden[key] = true
+	t.render()
+}
+
+// ShowColumns shows every hidden column again.
+func (t *objectTable) ShowColumns() {
+	clear(t.hidden)
+	t.render()
+}
+
+// Status describes the rows and hidden columns of the table for the title of
+// its pane.
+func (t *objectTable) Status() string {
+	if t.columns == nil && t.rows == nil {
+		return ""
+	}
+
+	status := fmt.Sprintf("%d rows", len(t.rows))
+	if len(t.rows) == 1 {
+		status = "1 row"
+	}
+
+	hidden := len(t.columns) - len(t.visibleColumns())
+	switch {
+	case hidden == 1:
+		status += " · 1 hidden column"
+	case hidden > 1:
+		status += fmt.Sprintf(" · %d hidden columns", hidden)
+	}
+
+	return status
+}
+
+// render fills the table from its rows, keeping the selected column.
+func (t *objectTable) render() {
+	row, _ := t.view.GetSelection()
+	selected, _ := t.selectedKey()
+
+	t.view.Clear()
+
+	columns := t.visibleColumns()
+	for i, key := range columns {
+		text := tview.Escape(key)
+		switch {
+		case t.sorted && key == t.sortKey && t.descending:
+			text += " ▼"
+		case t.sorted && key == t.sortKey:
+			text += " ▲"
+		}
+
+		t.view.SetCell(0, i, tview.NewTableCell(text).
+			SetAttributes(tcell.AttrBold).
+			SetMaxWidth(tableCellWidth).
+			SetSelectable(false))
+	}
+
+	rows := slices.Clone(t.rows)
+	if t.sorted {
+		slices.SortStableFunc(rows, func(a, b map[string]any) int {
+			c := compareMembers(a, b, t.sortKey)
+			if t.descending {
+				return -c
+			}
+
+			return c
+		})
+	}
+
+	for i, r := range rows {
+		for j, key := range columns {
+			v, ok := r[key]
+			cell := tview.NewTableCell(t.cellText(v, ok)).SetMaxWidth(tableCellWidth)
+			if _, isNumber := v.(json.Number); isNumber {
+				cell.SetAlign(tview.AlignRight)
+			}
+
+			t.view.SetCell(i+1, j, cell)
+		}
+	}
+
+	column := max(slices.Index(columns, selected), 0)
+	t.view.Select(min(max(row, 1), len(rows)), column)
+}
+
+// cellText returns the text of the cell for a value. Strings are shown
+// without quotes and objects and arrays as compact JSON.
+func (t *objectTable) cellText(v any, ok bool) string {
+	if !ok {
+		return ""
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return t.colors.null + "null[-:-:-]"
+	case bool:
+		if v {
+			return t.colors.trueValue + "true[-:-:-]"
+		}
+
+		return t.colors.falseValue + "false[-:-:-]"
+	case json.Number:
+		return t.colors.number + v.String() + "[-:-:-]"
+	case string:
+		s := strings.NewReplacer("\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(v)
+		return t.colors.str + tview.Escape(s) + "[-:-:-]"
+	case orderedObject:
+		return t.colors.object + tview.Escape(compactJSON(v)) + "[-:-:-]"
+	default:
+		return t.colors.array + tview.Escape(compactJSON(v)) + "[-:-:-]"
+	}
+}
+
+// compactJSON returns v as compact JSON.
+func compactJSON(v any) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// compareMembers compares the members of a and b with the given key. Missing
+// members sort before every value.
+func compareMembers(a, b map[string]any, key string) int {
+	va, oka := a[key]
+	vb, okb := b[key]
+	switch {
+	case !oka || !okb:
+		return cmp.Compare(boolRank(oka), boolRank(okb))
+	default:
+		return compareJSON(va, vb)
+	}
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// compareJSON compares two JSON values in the order jq sorts them: null,
+// false, true, numbers, strings, arrays and then objects.
+func compareJSON(a, b any) int {
+	if c := cmp.Compare(jsonRank(a), jsonRank(b)); c != 0 {
+		return c
+	}
+
+	switch a := a.(type) {
+	case json.Number:
+		x, _ := new(big.Float).SetString(a.String())
+		y, _ := new(big.Float).SetString(b.(json.Number).String())
+		if x == nil || y == nil {
+			return cmp.Compare(a.String(), b.(json.Number).String())
+		}
+
+		return x.Cmp(y)
+	case string:
+		return cmp.Compare(a, b.(string))
+	case []any:
+		return slices.CompareFunc(a, b.([]any), compareJSON)
+	case orderedObject:
+		// Objects are compared by their sorted keys, then by the
+		// values of those keys
+		x, y := a.sorted(), b.(orderedObject).sorted()
+		if c := slices.CompareFunc(x, y, func(e, f objectEntry) int { return cmp.Compare(e.key, f.key) }); c != 0 {
+			return c
+		}
+
+		return slices.CompareFunc(x, y, func(e, f objectEntry) int { return compareJSON(e.value, f.value) })
+	default:
+		return 0
+	}
+}
+
+func jsonRank(v any) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+
+		return 1
+	case json.Number:
+		return 3
+	case string:
+		return 4
+	case []any:
+		return 5
+	default:
+		return 6
+	}
+}
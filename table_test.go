@@ -0,0 +1,182 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tableText returns the text of each cell of a table without style tags.
+func tableText(table *objectTable) [][]string {
+	var rows [][]string
+	for i := range table.view.GetRowCount() {
+		var row []string
+		for j := range table.view.GetColumnCount() {
+			text := ""
+			if cell := table.view.GetCell(i, j); cell != nil {
+				for _, t := range splitTags(cell.Text) {
+					if !t.tag {
+						text += t.text
+					}
+				}
+			}
+
+			row = append(row, text)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+func TestTableObjects(t *testing.T) {
+	a := orderedObject{{key: "a", value: json.Number("1")}}
+
+	objects, ok := tableObjects([]any{[]any{a, a}})
+	assert.True(t, ok)
+	assert.Len(t, objects, 2)
+
+	objects, ok = tableObjects([]any{a, a, a})
+	assert.True(t, ok)
+	assert.Len(t, objects, 3)
+
+	_, ok = tableObjects([]any{[]any{a, json.Number("1")}})
+	assert.False(t, ok)
+
+	_, ok = tableObjects([]any{[]any{a}, []any{a}})
+	assert.False(t, ok)
+}
+
+func TestObjectTable(t *testing.T) {
+	table := newObjectTable()
+	table.SetValues([]any{[]any{
+		orderedObject{{key: "name", value: "b"}, {key: "size", value: json.Number("10")}},
+		orderedObject{{key: "name", value: "a\tb"}, {key: "tags", value: []any{"x", "[y]"}}},
+		orderedObject{{key: "size", value: json.Number("9")}, {key: "ok", value: true}, {key: "name", value: nil}},
+	}})
+
+	// Columns are the union of the keys in the order they are first seen
+	assert.Equal(t, [][]string{
+		{"name", "size", "tags", "ok"},
+		{"b", "10", "", ""},
+		{`a\tb`, "", `["x","[y]"]`, ""},
+		{"null", "9", "", "true"},
+	}, tableText(table))
+	assert.Equal(t, "3 rows", table.Status())
+
+	// Numbers sort by value and missing members sort first
+	table.view.Select(1, 1)
+	table.SortSelected()
+	assert.Equal(t, [][]string{
+		{"name", "size ▲", "tags", "ok"},
+		{`a\tb`, "", `["x","[y]"]`, ""},
+		{"null", "9", "", "true"},
+		{"b", "10", "", ""},
+	}, tableText(table))
+
+	table.SortSelected()
+	assert.Equal(t, "size ▼", tableText(table)[0][1])
+	assert.Equal(t, "b", tableText(table)[1][0])
+
+	// Hidden columns and the sort order are kept when the values change
+	table.view.Select(1, 2)
+	table.HideSelected()
+	table.SetValues([]any{
+		orderedObject{{key: "size", value: json.Number("1")}, {key: "tags", value: nil}},
+		orderedObject{{key: "size", value: json.Number("2")}},
+	})
+	assert.Equal(t, [][]string{
+		{"size ▼"},
+		{"2"},
+		{"1"},
+	}, tableText(table))
+	assert.Equal(t, "2 rows · 1 hidden column", table.Status())
+
+	// The last visible column cannot be hidden
+	table.HideSelected()
+	assert.Len(t, tableText(table)[0], 1)
+
+	table.ShowColumns()
+	assert.Equal(t, []string{"size ▼", "tags"}, tableText(table)[0])
+
+	table.SetValues([]any{json.Number("1")})
+	assert.Equal(t, [][]string{{"Output is not an array of objects"}}, tableText(table))
+	assert.Empty(t, table.Status())
+}
+
+func TestObjectTableSortsObjects(t *testing.T) {
+	object := func(entries ...any) orderedObject {
+		var o orderedObject
+		for i := 0; i < len(entries); i += 2 {
+			o = append(o, objectEntry{entries[i].(string), entries[i+1]})
+		}
+
+		return o
+	}
+
+	table := newObjectTable()
+	table.SetValues([]any{[]any{
+		orderedObject{{key: "v", value: object("n", json.Number("10"))}},
+		orderedObject{{key: "v", value: object("a", json.Number("1"), "b", json.Number("0"))}},
+		orderedObject{{key: "v", value: object("n", json.Number("9"))}},
+		orderedObject{{key: "v", value: object("b", json.Number("1"), "a", json.Number("0"))}},
+	}})
+
+	// Objects sort as in jq, by their sorted keys and then by the values
+	// of those keys, whatever order their members are in
+	table.view.Select(1, 0)
+	table.SortSelected()
+	assert.Equal(t, [][]string{
+		{"v ▲"},
+		{`{"b":1,"a":0}`},
+		{`{"a":1,"b":0}`},
+		{`{"n":9}`},
+		{`{"n":10}`},
+	}, tableText(table))
+}
+
+func TestCompareJSON(t *testing.T) {
+	values := []any{
+		nil,
+		false,
+		true,
+		json.Number("-1"),
+		json.Number("2"),
+		json.Number("10"),
+		"10",
+		"9",
+		[]any{json.Number("1")},
+		[]any{json.Number("1"), json.Number("2")},
+		orderedObject{},
+		orderedObject{{key: "a", value: json.Number("9")}},
+		orderedObject{{key: "a", value: json.Number("10")}},
+		orderedObject{{key: "b", value: json.Number("0")}, {key: "a", value: json.Number("10")}},
+		orderedObject{{key: "b", value: json.Number("0")}},
+	}
+
+	for i := range values {
+		for j := range values {
+			assert.Equal(t, cmp.Compare(i, j), compareJSON(values[i], values[j]), "%v <=> %v", values[i], values[j])
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// inputTab holds the input read from a single file.
+type inputTab struct {
+	name        string
+	compression string
+
+	// input is the contents of the file, converted into JSON. If the
+	// conversion changed the contents, original holds the text as it was
+	// read.
+	input    string
+	original string
+}
+
+// file returns the file the tab's input was read from.
+func (t inputTab) file() inputFile {
+	return inputFile{name: t.name}
+}
+
+// title returns the name of the tab shown in the title of the input pane.
+func (t inputTab) title() string {
+	if t.compression != "" {
+		return fmt.Sprintf("%s (%s)", t.name, t.compression)
+	}
+
+	return t.name
+}
+
+// readTabs reads each of the given files, decompressing them if necessary.
+func readTabs(files []string) ([]inputTab, error) {
+	tabs := make([]inputTab, len(files))
+	for i, name := range files {
+		var b strings.Builder
+		compression, err := readFile(&b, name)
+		if err != nil {
+			return nil, err
+		}
+
+		tabs[i] = inputTab{name: name, compression: compression, input: b.String()}
+	}
+
+	return tabs, nil
+}
+
+// convertTabs converts the input of each tab into JSON from the document's
+// input format and updates the input of the document.
+func (d *Document) convertTabs() error {
+	// Other copies of the document share the tabs, so they must not be
+	// modified in place
+	tabs := slices.Clone(d.tabs)
+	for i, t := range tabs {
+		text := cmp.Or(t.original, t.input)
+		converted, err := convertInput(text, d.options.InputFormat)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+
+		tabs[i].input = converted
+		tabs[i].original = ""
+		if converted != text {
+			tabs[i].original = text
+		}
+	}
+
+	d.tabs = tabs
+	d.selectTab(d.selected)
+	return nil
+}
+
+// selectTab selects the tab at index i, wrapping around at either end, and
+// updates the input of the document. Unless -tabs is set the input is the
+// contents of every tab.
+func (d *Document) selectTab(i int) {
+	n := len(d.tabs)
+	if n == 0 {
+		return
+	}
+
+	d.selected = (i%n + n) % n
+	if d.options.Tabs {
+		t := d.tabs[d.selected]
+		d.input, d.original = t.input, t.original
+		return
+	}
+
+	var input, original strings.Builder
+	converted := false
+	for _, t := range d.tabs {
+		input.WriteString(t.input)
+		original.WriteString(cmp.Or(t.original, t.input))
+		converted = converted || t.original != ""
+	}
+
+	d.input = input.String()
+	d.original = ""
+	if converted {
+		d.original = original.String()
+	}
+}
+
+// writeTabs evaluates the filter against the input of each tab in turn and
+// writes the output to w. When writing to a pane the output of each tab is
+// preceded by a header naming its file.
+func (d Document) writeTabs(evaluator Evaluator, opts options.Options, w io.Writer, isPane bool) error {
+	var (
+		stderr []byte
+		code   int
+	)
+
+	for i, t := range d.tabs {
+		if isPane {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "\x1b[1m==> %s <==\x1b[0m\n", tview.Escape(t.name))
+		}
+
+		td := d
+		td.input = t.input
+		td.ctx = withInputFile(d.ctx, t.file())
+		if err := td.write(evaluator, opts, w, isPane); err != nil {
+			out, ok := errorOutput(err)
+			if !ok {
+				return err
+			}
+
+			// Keep going so that the output of the remaining files
+			// is still shown, and report every error at the end
+			stderr = append(stderr, out...)
+			code = exitCode(err)
+		}
+	}
+
+	if code != 0 {
+		return &EvalError{Stderr: stderr, Code: code}
+	}
+
+	return nil
+}
+
+// describeSource returns a description of where the input of the document
+// was read from, shown in the title of the input pane. With -tabs this names
+// the selected tab.
+func (d Document) describeSource() string {
+	if len(d.tabs) == 0 {
+		return d.source
+	}
+
+	if d.options.Tabs {
+		return fmt.Sprintf("%s (%d of %d)", d.tabs[d.selected].title(), d.selected+1, len(d.tabs))
+	}
+
+	names := make([]string, len(d.tabs))
+	compressions := make([]string, len(d.tabs))
+	for i, t := range d.tabs {
+		names[i], compressions[i] = t.name, t.compression
+	}
+
+	return describeInput(names, compressions)
+}
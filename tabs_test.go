@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+func writeTabFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := make([]string, len(contents))
+	for i, content := range contents {
+		files[i] = filepath.Join(dir, string(rune('a'+i))+".json")
+		require.NoError(t, os.WriteFile(files[i], []byte(content), 0o644))
+	}
+
+	return files
+}
+
+func TestReadTabs(t *testing.T) {
+	files := writeTabFiles(t, "1\n", "2\n")
+
+	tabs, err := readTabs(files)
+	require.NoError(t, err)
+	require.Len(t, tabs, 2)
+	assert.Equal(t, inputTab{name: files[0], input: "1\n"}, tabs[0])
+	assert.Equal(t, inputTab{name: files[1], input: "2\n"}, tabs[1])
+
+	_, err = readTabs([]string{filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+}
+
+func TestDocumentSelectTab(t *testing.T) {
+	doc := Document{tabs: []inputTab{
+		{name: "a.json", input: "1\n"},
+		{name: "b.json", input: "2\n"},
+		{name: "c.json", input: "3\n"},
+	}}
+
+	// Without -tabs the input is every file
+	doc.selectTab(1)
+	assert.Equal(t, "1\n2\n3\n", doc.input)
+	assert.Empty(t, doc.describeSource())
+
+	doc.options.Tabs = true
+	doc.selectTab(1)
+	assert.Equal(t, "2\n", doc.input)
+	assert.Equal(t, "b.json (2 of 3)", doc.describeSource())
+
+	doc.selectTab(3)
+	assert.Equal(t, "1\n", doc.input)
+
+	doc.selectTab(-1)
+	assert.Equal(t, "3\n", doc.input)
+}
+
+func TestDocumentConvertTabs(t *testing.T) {
+	doc := Document{
+		tabs: []inputTab{
+			{name: "a.yaml", input: "a: 1\n"},
+			{name: "b.yaml", input: "b: 2\n"},
+		},
+		options: options.Options{InputFormat: "yaml", Tabs: true},
+	}
+
+	require.NoError(t, doc.convertTabs())
+	assert.Equal(t, `{"a":1}`+"\n", doc.input)
+	assert.Equal(t, "a: 1\n", doc.original)
+
+	doc.options.Tabs = false
+	doc.selectTab(0)
+	assert.Equal(t, `{"a":1}`+"\n"+`{"b":2}`+"\n", doc.input)
+	assert.Equal(t, "a: 1\nb: 2\n", doc.original)
+
+	doc.tabs[1].original = ": :"
+	err := doc.convertTabs()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "b.yaml")
+}
+
+func TestDocumentWriteToTabs(t *testing.T) {
+	doc := Document{
+		tabs: []inputTab{
+			{name: "a.json", input: `{"n":1}`},
+			{name: "b.json", input: `{"n":"x"}`},
+			{name: "c.json", input: `{"n":3}`},
+		},
+		filter:    "[input_filename, .n + 1]",
+		options:   options.Options{CompactOutput: true, Tabs: true},
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		ctx:       context.Background(),
+	}
+
+	doc.selectTab(2)
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, `["c.json",4]`+"\n", buf.String())
+
+	// The filter is run against each file in turn, and errors do not stop
+	// the remaining files from being processed
+	doc.options.TabScope = "all"
+	buf.Reset()
+	_, err = doc.WriteTo(&buf)
+	assert.Equal(t, `["a.json",2]`+"\n"+`["c.json",4]`+"\n", buf.String())
+	stderr, ok := errorOutput(err)
+	require.True(t, ok)
+	assert.Contains(t, stderr, "(at b.json)")
+	assert.Equal(t, exitRuntime, exitCode(err))
+}
+
+func TestExecEvaluatorInputFilename(t *testing.T) {
+	if _, err := exec.LookPath("jq"); err != nil {
+		t.Skip("jq is not installed")
+	}
+
+	// The input ijq loaded is used even if the file has since changed
+	files := writeTabFiles(t, "1\n")
+	require.NoError(t, os.WriteFile(files[0], []byte("2\n"), 0o644))
+	ctx := withInputFile(context.Background(), inputFile{name: files[0]})
+
+	var buf bytes.Buffer
+	opts := options.Options{JQCommand: "jq", Monochrome: true, CompactOutput: true}
+	require.NoError(t, execEvaluator{}.Evaluate(ctx, "1\n", "[input_filename, .]", opts, &buf))
+	assert.Equal(t, `["`+files[0]+`",1]`+"\n", buf.String())
+
+	// Errors quote the filter as it was written
+	err := execEvaluator{}.Evaluate(ctx, "1\n", "input_filename | foo", opts, &buf)
+	stderr, ok := errorOutput(err)
+	require.True(t, ok)
+	assert.Contains(t, stderr, "foo/0 is not defined")
+	assert.NotContains(t, stderr, "def input_filename")
+}
+
+func TestInsertDefinition(t *testing.T) {
+	def := "def f: 1; "
+	for filter, want := range map[string]string{
+		".":                             "def f: 1; .",
+		"":                              "",
+		"# comment":                     "# comment",
+		`import "a" as a; .`:            `import "a" as a;def f: 1;  .`,
+		`module {a: 1}; include "b"; f`: `module {a: 1}; include "b";def f: 1;  f`,
+		`import "a" as a;`:              `import "a" as a;`,
+		"import \"a\" as a;\n# x; y\n.": "import \"a\" as a;def f: 1; \n# x; y\n.",
+	} {
+		assert.Equal(t, want, insertDefinition(filter, def), filter)
+	}
+}
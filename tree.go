@@ -0,0 +1,367 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// treeDepth is the depth up to which nodes are unfolded when they are first
+// shown in a tree.
+const treeDepth = 2
+
+// values evaluates the filter and returns its output as JSON values, keeping
+// the order of object keys.
+func (d Document) values() ([]any, error) {
+	d.options = jsonOptions(d.options)
+	d.options.OutputFormat = ""
+
+	var buf bytes.Buffer
+	_, evalErr := d.WriteTo(&buf)
+	if evalErr != nil {
+		if _, ok := errorOutput(evalErr); !ok {
+			return nil, evalErr
+		}
+	}
+
+	values, err := decodeOrderedValues(buf.String())
+	if err != nil {
+		if evalErr != nil {
+			return nil, evalErr
+		}
+
+		return nil, err
+	}
+
+	return values, evalErr
+}
+
+// valueView shows the JSON values of a pane in place of its text.
+type valueView interface {
+	// Enabled reports whether the view is shown
+	Enabled() bool
+	SetValues(values []any)
+}
+
+// jsonTree shows JSON values in a tree whose objects and arrays can be folded
+// and unfolded. It is shown in place of the text of a pane.
+type jsonTree struct {
+	view    *tview.TreeView
+	enabled atomic.Bool
+
+	// Whether each node has been folded or unfolded, by path, so that
+	// folds are kept when the values change
+	expanded map[string]bool
+
+	// Style tags for each kind of value, following jq's colors
+	colors jsonColors
+}
+
+// treeValue is the reference of each node in a tree.
+type treeValue struct {
+	// path holds the object keys and array indices leading to the value
+	// from the top-level value, whose index is the first element
+	path  []any
+	value any
+}
+
+// jsonColorTags returns jq's colors, as set by JQ_COLORS, as style tags.
+func jsonColorTags() jsonColors {
+	colors := parseJQColors(os.Getenv("JQ_COLORS"))
+	for _, c := range []*string{
+		&colors.null, &colors.falseValue, &colors.trueValue, &colors.number,
+		&colors.str, &colors.array, &colors.object, &colors.objectKey,
+	} {
+		*c = tview.TranslateANSI(*c)
+	}
+
+	return colors
+}
+
+func newJSONTree() *jsonTree {
+	t := &jsonTree{
+		view:     tview.NewTreeView(),
+		expanded: make(map[string]bool),
+		colors:   jsonColorTags(),
+	}
+
+	t.view.SetTopLevel(1).SetRoot(tview.NewTreeNode("")).SetBorder(true)
+	t.view.SetSelectedFunc(func(node *tview.TreeNode) {
+		t.SetExpanded(node, !node.IsExpanded())
+	})
+
+	return t
+}
+
+// Enabled reports whether the tree is shown in place of the text of its pane.
+func (t *jsonTree) Enabled() bool {
+	return t.enabled.Load()
+}
+
+// SetValues replaces the values shown in the tree. The selected node is kept
+// if a value with the same path still exists.
+func (t *jsonTree) SetValues(values []any) {
+	var selected []any
+	if node := t.view.GetCurrentNode(); node != nil {
+		if ref, ok := node.GetReference().(treeValue); ok {
+			selected = ref.path
+		}
+	}
+
+	root := t.view.GetRoot()
+	root.ClearChildren()
+	for i, v := range values {
+		root.AddChild(t.newNode(treeValue{path: []any{i}, value: v}))
+	}
+
+	current := root
+	for _, node := range root.GetChildren() {
+		if found := t.find(node, selected); found != nil {
+			current = found
+			break
+		}
+	}
+
+	if current == root && len(root.GetChildren()) > 0 {
+		current = root.GetChildren()[0]
+	}
+
+	t.view.SetCurrentNode(current)
+}
+
+// find returns the node with the given path below node, if it is visible.
+func (t *jsonTree) find(node *tview.TreeNode, path []any) *tview.TreeNode {
+	ref := node.GetReference().(treeValue)
+	if len(path) < len(ref.path) {
+		return nil
+	}
+
+	for i, p := range ref.path {
+		if path[i] != p {
+			return nil
+		}
+	}
+
+	if len(path) == len(ref.path) {
+		return node
+	}
+
+	if !node.IsExpanded() {
+		return nil
+	}
+
+	for _, child := range node.GetChildren() {
+		if found := t.find(child, path); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// SetExpanded unfolds or folds a node. Folding a node which is already folded
+// or which has no children folds its parent instead.
+func (t *jsonTree) SetExpanded(node *tview.TreeNode, expanded bool) {
+	ref, ok := node.GetReference().(treeValue)
+	if !ok {
+		return
+	}
+
+	if !expanded && (!node.IsExpanded() || !hasChildren(ref.value)) {
+		path := t.view.GetPath(node)
+		if len(path) > 2 {
+			parent := path[len(path)-2]
+			t.SetExpanded(parent, false)
+			t.view.SetCurrentNode(parent)
+		}
+
+		return
+	}
+
+	if !hasChildren(ref.value) {
+		return
+	}
+
+	t.expanded[pathKey(ref.path)] = expanded
+	t.setExpanded(node, ref, expanded)
+}
+
+func (t *jsonTree) setExpanded(node *tview.TreeNode, ref treeValue, expanded bool) {
+	if expanded && len(node.GetChildren()) == 0 {
+		// Children are only created once a node is unfolded, so that
+		// large documents can be shown quickly
+		eachChild(ref.value, func(key any, v any) {
+			path := append(ref.path[:len(ref.path):len(ref.path)], key)
+			node.AddChild(t.newNode(treeValue{path: path, value: v}))
+		})
+	}
+
+	node.SetExpanded(expanded)
+	node.SetText(t.label(ref, expanded))
+}
+
+func (t *jsonTree) newNode(ref treeValue) *tview.TreeNode {
+	node := tview.NewTreeNode("").
+		SetReference(ref).
+		SetSelectable(true).
+		SetSelectedTextStyle(tcell.StyleDefault.Reverse(true))
+
+	expanded, ok := t.expanded[pathKey(ref.path)]
+	if !ok {
+		expanded = len(ref.path) <= treeDepth
+	}
+
+	t.setExpanded(node, ref, expanded && hasChildren(ref.value))
+	return node
+}
+
+// label returns the text of the node for a value.
+func (t *jsonTree) label(ref treeValue, expanded bool) string {
+	var b strings.Builder
+
+	marker := "  "
+	if hasChildren(ref.value) {
+		marker = "▸ "
+		if expanded {
+			marker = "▾ "
+		}
+	}
+
+	b.WriteString(marker)
+
+	switch key := ref.path[len(ref.path)-1].(type) {
+	case string:
+		if len(ref.path) > 1 {
+			fmt.Fprintf(&b, "%s%s[-:-:-]%s:[-:-:-] ", t.colors.objectKey, tview.Escape(quoteJSON(key)), t.colors.object)
+		}
+	case int:
+		if len(ref.path) > 1 {
+			fmt.Fprintf(&b, "[::d]%d:[::D] ", key)
+		}
+	}
+
+	count := func(n int, noun string) string {
+		if n == 1 {
+			return fmt.Sprintf(" [::d]1 %s[::D]", noun)
+		}
+
+		return fmt.Sprintf(" [::d]%d %ss[::D]", n, noun)
+	}
+
+	switch v := ref.value.(type) {
+	case orderedObject:
+		b.WriteString(t.colors.object)
+		switch {
+		case len(v) == 0:
+			b.WriteString("{}[-:-:-]")
+		case expanded:
+			b.WriteString("{[-:-:-]")
+		default:
+			b.WriteString("{…}[-:-:-]" + count(len(v), "key"))
+		}
+	case []any:
+		b.WriteString(t.colors.array)
+		switch {
+		case len(v) == 0:
+			b.WriteString("[][-:-:-]")
+		case expanded:
+			b.WriteString("[[-:-:-]")
+		default:
+			b.WriteString("[…][-:-:-]" + count(len(v), "item"))
+		}
+	case nil:
+		b.WriteString(t.colors.null + "null[-:-:-]")
+	case bool:
+		if v {
+			b.WriteString(t.colors.trueValue + "true[-:-:-]")
+		} else {
+			b.WriteString(t.colors.falseValue + "false[-:-:-]")
+		}
+	case json.Number:
+		b.WriteString(t.colors.number + v.String() + "[-:-:-]")
+	case string:
+		b.WriteString(t.colors.str + tview.Escape(quoteJSON(v)) + "[-:-:-]")
+	default:
+		fmt.Fprintf(&b, "%v", v)
+	}
+
+	return b.String()
+}
+
+// hasChildren reports whether v is a non-empty object or array.
+func hasChildren(v any) bool {
+	switch v := v.(type) {
+	case orderedObject:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	default:
+		return false
+	}
+}
+
+// eachChild calls f with the key and value of each member of an object, or
+// the index and value of each element of an array.
+func eachChild(v any, f func(key any, v any)) {
+	switch v := v.(type) {
+	case orderedObject:
+		for _, entry := range v {
+			f(entry.key, entry.value)
+		}
+	case []any:
+		for i, x := range v {
+			f(i, x)
+		}
+	}
+}
+
+// pathKey returns a string which identifies a path in a tree.
+func pathKey(path []any) string {
+	var b strings.Builder
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			b.WriteString(strconv.Itoa(p))
+		case string:
+			b.WriteString(strconv.Quote(p))
+		}
+
+		b.WriteByte('/')
+	}
+
+	return b.String()
+}
+
+// quoteJSON returns s as a JSON string, escaped the same way jq escapes it.
+func quoteJSON(s string) string {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	(&jsonEncoder{}).writeString(w, s, true)
+	w.Flush()
+	return b.String()
+}
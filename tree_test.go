@@ -0,0 +1,154 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"codeberg.org/gpanders/ijq/internal/options"
+)
+
+// treeLines returns the text of each visible node of a tree without style
+// tags, indented by depth.
+func treeLines(tree *jsonTree) []string {
+	var (
+		lines []string
+		walk  func(node *tview.TreeNode, depth int)
+	)
+
+	walk = func(node *tview.TreeNode, depth int) {
+		for _, child := range node.GetChildren() {
+			var b strings.Builder
+			for _, t := range splitTags(child.GetText()) {
+				if !t.tag {
+					b.WriteString(t.text)
+				}
+			}
+
+			lines = append(lines, strings.Repeat("  ", depth)+b.String())
+			if child.IsExpanded() {
+				walk(child, depth+1)
+			}
+		}
+	}
+
+	walk(tree.view.GetRoot(), 0)
+	return lines
+}
+
+func TestDocumentValues(t *testing.T) {
+	doc := Document{
+		ctx:       context.Background(),
+		input:     `{"a":[true,null],"b":1} "x"`,
+		filter:    ".",
+		evaluator: newEvaluator(options.Options{Engine: "gojq"}),
+		options:   options.Options{OutputFormat: "yaml", RawOutput: true},
+	}
+
+	values, err := doc.values()
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		orderedObject{{key: "a", value: []any{true, nil}}, {key: "b", value: json.Number("1")}},
+		"x",
+	}, values)
+
+	// Output before an error is kept
+	doc = doc.WithFilter(`1, error("oops")`)
+	doc.input = "null"
+	values, err = doc.values()
+	assert.Error(t, err)
+	assert.Equal(t, []any{json.Number("1")}, values)
+}
+
+func TestJSONTreeLabels(t *testing.T) {
+	tree := newJSONTree()
+	tree.SetValues([]any{
+		orderedObject{
+			{key: "a", value: orderedObject{{key: "b", value: []any{json.Number("1"), "[x]"}}}},
+			{key: "c", value: orderedObject{}},
+			{key: "d", value: []any{}},
+			{key: "e", value: false},
+		},
+		nil,
+	})
+
+	// Values are unfolded up to the children of the top-level values
+	assert.Equal(t, []string{
+		"▾ {",
+		`  ▾ "a": {`,
+		`    ▸ "b": […] 2 items`,
+		`    "c": {}`,
+		`    "d": []`,
+		`    "e": false`,
+		"  null",
+	}, treeLines(tree))
+}
+
+func TestJSONTreeExpand(t *testing.T) {
+	tree := newJSONTree()
+	values := []any{
+		orderedObject{
+			{key: "a", value: []any{orderedObject{{key: "x", value: json.Number("1")}}}},
+			{key: "b", value: json.Number("2")},
+		},
+	}
+
+	tree.SetValues(values)
+
+	top := tree.view.GetRoot().GetChildren()[0]
+	a := top.GetChildren()[0]
+	element := a.GetChildren()[0]
+	assert.Equal(t, []string{
+		"▾ {",
+		`  ▾ "a": [`,
+		"    ▸ 0: {…} 1 key",
+		`    "b": 2`,
+	}, treeLines(tree))
+
+	tree.SetExpanded(element, true)
+	assert.Equal(t, `        "x": 1`, treeLines(tree)[3])
+
+	// Folding a value which is already folded folds its parent
+	tree.SetExpanded(element, false)
+	tree.SetExpanded(element, false)
+	assert.Same(t, a, tree.view.GetCurrentNode())
+	assert.Equal(t, []string{
+		"▾ {",
+		`  ▸ "a": […] 1 item`,
+		`    "b": 2`,
+	}, treeLines(tree))
+
+	// Folds and the selected value are kept when the values change
+	tree.SetValues(values)
+	assert.Equal(t, []string{
+		"▾ {",
+		`  ▸ "a": […] 1 item`,
+		`    "b": 2`,
+	}, treeLines(tree))
+
+	ref, ok := tree.view.GetCurrentNode().GetReference().(treeValue)
+	require.True(t, ok)
+	assert.Equal(t, []any{0, "a"}, ref.path)
+}
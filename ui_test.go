@@ -2,12 +2,15 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -34,10 +37,26 @@ type testApp struct {
 	screen      tcell.SimulationScreen
 	runErr      chan error
 	historyPath string
+	status      int
 }
 
 func newTestApp(t *testing.T, input string, historyEntries []string) *testApp {
 	t.Helper()
+	return newTestAppWithEngine(t, input, historyEntries, "exec")
+}
+
+// newTestAppWithEngine starts an app which runs filters with engine. With
+// "exec" filters are run by testdata/catok, which outputs the input
+// unchanged, and with "gojq" they are actually run.
+func newTestAppWithEngine(t *testing.T, input string, historyEntries []string, engine options.Engine) *testApp {
+	t.Helper()
+	return newTestAppWithDocument(t, input, historyEntries, engine, nil)
+}
+
+// newTestAppWithDocument starts an app as newTestAppWithEngine does, with its
+// document changed by configure, which may be nil.
+func newTestAppWithDocument(t *testing.T, input string, historyEntries []string, engine options.Engine, configure func(*Document)) *testApp {
+	t.Helper()
 
 	if runtime.GOOS == "windows" {
 		t.Skip("ui tests rely on the shell-based testdata/catok helper")
@@ -64,24 +83,30 @@ func newTestApp(t *testing.T, input string, historyEntries []string) *testApp {
 		options: options.Options{
 			HistoryFile: cfg.HistoryFile,
 			JQCommand:   cfg.JQCommand,
+			Engine:      engine,
 		},
 		config: cfg,
 	}
-
-	app := createApp(doc)
-	screen := tcell.NewSimulationScreen("")
-	require.NoError(t, screen.Init())
-	app.SetScreen(screen)
-	screen.SetSize(testScreenWidth, testScreenHeight)
+	doc.evaluator = newEvaluator(doc.options)
+	if configure != nil {
+		configure(&doc)
+	}
 
 	ta := &testApp{
 		t:           t,
-		app:         app,
-		screen:      screen,
 		runErr:      make(chan error, 1),
 		historyPath: historyPath,
 	}
 
+	app := createApp(doc, &ta.status)
+	screen := tcell.NewSimulationScreen("")
+	require.NoError(t, screen.Init())
+	app.SetScreen(screen)
+	screen.SetSize(testScreenWidth, testScreenHeight)
+
+	ta.app = app
+	ta.screen = screen
+
 	go func() {
 		ta.runErr <- app.Run()
 	}()
@@ -135,6 +160,13 @@ func (ta *testApp) postRunes(text string) {
 	}
 }
 
+func (ta *testApp) click(x, y int) {
+	ta.t.Helper()
+	ta.app.QueueEvent(tcell.NewEventMouse(x, y, tcell.Button1, tcell.ModNone))
+	ta.app.QueueEvent(tcell.NewEventMouse(x, y, tcell.ButtonNone, tcell.ModNone))
+	time.Sleep(testRedrawDelay)
+}
+
 func (ta *testApp) rows() []string {
 	rows := []string(nil)
 	ta.app.QueueUpdate(func() {
@@ -157,6 +189,15 @@ func (ta *testApp) rows() []string {
 	return rows
 }
 
+func (ta *testApp) style(x, y int) tcell.Style {
+	var style tcell.Style
+	ta.app.QueueUpdate(func() {
+		_, _, style, _ = ta.screen.GetContent(x, y)
+	})
+
+	return style
+}
+
 func (ta *testApp) row(y int) string {
 	ta.t.Helper()
 	rows := ta.rows()
@@ -314,6 +355,138 @@ func TestUIFilterInput(t *testing.T) {
 	ta.requireNoText(".foo.bar")
 }
 
+func TestUIBuiltinAutocomplete(t *testing.T) {
+	ta := newTestApp(t, `{"key":"value"}`, nil)
+
+	ta.postRunes(" | to_en")
+	ta.waitForText(". | to_entries", testActionTimeout)
+	ta.waitForText("to_entries  Converts an object to an array of key-value pairs.", testActionTimeout)
+
+	// The hint follows the selected entry
+	ta.postRunes("tries | sort")
+	ta.waitForText("sort  Sorts an array.", testActionTimeout)
+	ta.postKey(tcell.KeyTab, tcell.ModNone)
+	ta.waitForText("sort_by(f)  Sorts an array by the value of f.", testActionTimeout)
+
+	ta.postKey(tcell.KeyEsc, tcell.ModNone)
+	ta.waitForNoText("Sorts an array by the value of f.", testActionTimeout)
+}
+
+func TestUISymbolAutocomplete(t *testing.T) {
+	ta := newTestApp(t, `{"key":"value"}`, nil)
+
+	// Variables and functions defined in the filter are completed with
+	// their kinds
+	ta.postRunes(" as $item | def twice: . * 2; $it")
+	ta.waitForText("$item  variable", testActionTimeout)
+
+	ta.postRunes("em | twi")
+	ta.waitForText("twice  function", testActionTimeout)
+	ta.waitForText(". as $item | def twice: . * 2; $item | twice", testActionTimeout)
+}
+
+func TestUIKeyAutocomplete(t *testing.T) {
+	ta := newTestAppWithEngine(t, `{"items":[{"id":1,"app name":"x"}],"count":1}`, nil, "gojq")
+	filterRow := ta.findRowOf("Filter") + 1
+
+	// Keys are completed from the elements inside map
+	ta.postRunes("items | map(.")
+	ta.waitForText(`.items | map(."app name"`, testActionTimeout)
+	ta.waitForText(".items | map(.id", testActionTimeout)
+
+	ta.postRunes("id)")
+	ta.postKey(tcell.KeyEsc, tcell.ModNone)
+	ta.waitFor(func() bool {
+		return strings.Contains(ta.row(filterRow), "║.items | map(.id) ")
+	}, "filter to be typed", testActionTimeout)
+
+	// Keys are completed at the cursor, which stays after the key
+	ta.postKey(tcell.KeyLeft, tcell.ModNone)
+	ta.postKey(tcell.KeyBackspace2, tcell.ModNone)
+	ta.postKey(tcell.KeyBackspace2, tcell.ModNone)
+	ta.waitForText(`.items | map(."app name")`, testActionTimeout)
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.postRunes("[0]")
+	ta.waitFor(func() bool {
+		return strings.Contains(ta.row(filterRow), `║.items | map(."app name"[0]) `)
+	}, "cursor to stay after the key", testActionTimeout)
+}
+
+func TestUIKeyAutocompleteFuzzy(t *testing.T) {
+	ta := newTestAppWithDocument(t, `{"first_name":1,"last_name":2,"nickname":3,"id":4}`, nil, "gojq", func(doc *Document) {
+		doc.config.AutocompleteMatch = matchFuzzy
+	})
+
+	// Keys are ranked by how well they match, with the matched characters
+	// highlighted
+	ta.postRunes("name")
+	ta.waitForText(".nickname", testActionTimeout)
+	last, first, nick := ta.findRowOf(".last_name"), ta.findRowOf(".first_name"), ta.findRowOf(".nickname")
+	require.Less(t, last, first)
+	require.Less(t, first, nick)
+	require.Equal(t, -1, ta.findRowOf(".id"))
+
+	row := ta.row(first)
+	x := utf8.RuneCountInString(row[:strings.Index(row, ".first_name")])
+	_, _, attrs := ta.style(x+len(".first_"), first).Decompose()
+	require.NotZero(t, attrs&tcell.AttrUnderline)
+	_, _, attrs = ta.style(x+len(".first"), first).Decompose()
+	require.Zero(t, attrs&tcell.AttrUnderline)
+}
+
+func TestUIKeyAutocompleteSlurp(t *testing.T) {
+	ta := newTestAppWithEngine(t, `{"a":{"x":1}}`, nil, "gojq")
+
+	ta.postRunes("[] | .")
+	ta.waitForText(".[] | .x", testActionTimeout)
+	ta.postKey(tcell.KeyEsc, tcell.ModNone)
+	ta.postKey(tcell.KeyBackspace2, tcell.ModNone)
+
+	ta.openMenu()
+	ta.selectMenuItem(0)
+	ta.waitForText("○ Slurp input (-s)", testActionTimeout)
+	ta.postKey(tcell.KeyDown, tcell.ModNone)
+	ta.postKey(tcell.KeyDown, tcell.ModNone)
+	ta.postRune(' ')
+	ta.waitForText("● Slurp input (-s)", testActionTimeout)
+	ta.postKey(tcell.KeyCtrlUnderscore, tcell.ModNone)
+	ta.waitForNoText("Configure", testActionTimeout)
+
+	// The keys looked up before slurping are not completed
+	ta.postRune('.')
+	ta.waitForText(".[] | .a", testActionTimeout)
+	ta.requireNoText(".[] | .x")
+}
+
+func TestUIStream(t *testing.T) {
+	ta := newTestAppWithDocument(t, `{"a":{"b":1}}`, nil, "gojq", func(doc *Document) {
+		doc.options.Stream = true
+	})
+
+	// Each event is shown on its own line
+	ta.waitForText(`[["a","b"],1]`, testActionTimeout)
+	ta.waitForText(`[["a","b"]]`, testActionTimeout)
+	ta.waitForText(`[["a"]]`, testActionTimeout)
+}
+
+func TestUICtrlCExitStatus(t *testing.T) {
+	ta := newTestApp(t, `{"key":"value"}`, nil)
+
+	// The first Ctrl-C clears the filter, the second quits
+	ta.postKey(tcell.KeyCtrlC, tcell.ModNone)
+	ta.postKey(tcell.KeyCtrlC, tcell.ModNone)
+
+	select {
+	case err := <-ta.runErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for app to stop\n%s", ta.screenContent())
+	}
+
+	ta.runErr = nil
+	require.Equal(t, exitCancelled, ta.status)
+}
+
 func TestUIFocusMovement(t *testing.T) {
 	ta := newTestApp(t, generateLargeInput(100), nil)
 
@@ -409,6 +582,262 @@ func TestUIScrollKeybindingsAndIndicator(t *testing.T) {
 	ta.waitForText("Output (Bot)", testActionTimeout)
 }
 
+func TestUISearchPane(t *testing.T) {
+	ta := newTestApp(t, "alpha\nbeta\nalpha\n", nil)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+
+	ta.postRune('/')
+	ta.waitForInputFieldFocus(testActionTimeout)
+	ta.postRunes("alp")
+	ta.waitForText("Output (Top) · match 1 of 2", testActionTimeout)
+
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.waitForText("menu", testActionTimeout)
+
+	ta.postRune('n')
+	ta.waitForText("Output (Top) · match 2 of 2", testActionTimeout)
+
+	ta.postRune('N')
+	ta.waitForText("Output (Top) · match 1 of 2", testActionTimeout)
+
+	// The search survives the output being rendered again
+	ta.postKey(tcell.KeyDown, tcell.ModShift)
+	ta.waitForInputFieldFocus(testActionTimeout)
+	ta.postRunes(" ")
+	ta.waitForText("Output (Top) · match 1 of 2", testActionTimeout)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.postRune('?')
+	ta.waitForInputFieldFocus(testActionTimeout)
+	ta.postRunes("gamma")
+	ta.waitForText("Output (Top) · no matches", testActionTimeout)
+
+	ta.postKey(tcell.KeyEscape, tcell.ModNone)
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.waitForText("Output (Top)", testActionTimeout)
+	ta.requireNoText("matches")
+}
+
+func TestUITreeView(t *testing.T) {
+	ta := newTestApp(t, `{"a":{"b":[1,2]},"c":"x"}`, nil)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+
+	ta.postRune('t')
+	ta.waitForText(`"b": […] 2 items`, testActionTimeout)
+	ta.requireText("Output (Top)")
+
+	// Select "b" and unfold it
+	ta.postRune('j')
+	ta.postRune('j')
+	ta.postRune('l')
+	ta.waitForText(`"b": [`, testActionTimeout)
+	ta.waitForText("1: 2", testActionTimeout)
+
+	ta.postRune('h')
+	ta.waitForText(`"b": […] 2 items`, testActionTimeout)
+
+	ta.postRune('t')
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.waitForNoText("2 items", testActionTimeout)
+}
+
+func TestUITableView(t *testing.T) {
+	ta := newTestApp(t, `[{"name":"b","size":2},{"name":"a","size":1}]`, nil)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+
+	ta.postRune('T')
+	ta.waitForText("Output (Top) · 2 rows", testActionTimeout)
+	ta.requireText("name")
+
+	ta.postRune('s')
+	ta.waitForText("name ▲", testActionTimeout)
+	require.Less(t, ta.findRowOf("║a "), ta.findRowOf("║b "), ta.screenContent())
+
+	ta.postRune('l')
+	ta.postRune('x')
+	ta.waitForText("2 rows · 1 hidden column", testActionTimeout)
+	header := func() string {
+		row := ta.row(ta.findRowOf("name ▲"))
+		return row[strings.Index(row, "║"):]
+	}
+	require.NotContains(t, header(), "size")
+
+	ta.postRune('X')
+	ta.waitFor(func() bool {
+		return strings.Contains(header(), "size")
+	}, "hidden column to be shown", testActionTimeout)
+
+	ta.postRune('T')
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.waitForNoText("2 rows", testActionTimeout)
+}
+
+func TestUIDiffView(t *testing.T) {
+	ta := newTestApp(t, `{"a":1}`, nil)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+
+	ta.postRune('D')
+	ta.waitForText("diff with input · 0 added", testActionTimeout)
+	ta.requireText("No differences")
+
+	ta.postRune('B')
+	ta.waitForText("diff with . · 0 added", testActionTimeout)
+
+	ta.postRune('B')
+	ta.waitForText("diff with input", testActionTimeout)
+
+	ta.postRune('D')
+	ta.waitForNoText("No differences", testActionTimeout)
+}
+
+func TestUISnapshotPane(t *testing.T) {
+	ta := newTestApp(t, `{"snapshot":"pinned"}`, nil)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.waitForText(`"pinned"`, testActionTimeout)
+
+	ta.postRune('P')
+	ta.waitForText("Pin snapshot", testActionTimeout)
+	ta.postRunes("before")
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.waitForText("before (Top) · .", testActionTimeout)
+	ta.waitFor(func() bool {
+		return strings.Count(ta.row(ta.findRowOf(`"pinned"`)), `"pinned"`) == 3
+	}, "snapshot to be shown next to the output", testActionTimeout)
+
+	ta.postRune('S')
+	ta.waitForNoText("before (Top)", testActionTimeout)
+
+	ta.postRune('S')
+	ta.waitForText("before (Top)", testActionTimeout)
+
+	// The snapshot can be deleted from the overlay
+	ta.openMenu()
+	ta.selectMenuItem(5)
+	ta.waitForText("Snapshots (1)", testActionTimeout)
+	ta.postRune('x')
+	ta.waitForText("Snapshots (0) - deleted", testActionTimeout)
+	ta.requireNoText("before (Top)")
+}
+
+func TestUICopy(t *testing.T) {
+	ta := newTestApp(t, `{"a":1}`, nil)
+
+	// The simulation screen has no terminal to write OSC 52 sequences to
+	// and no clipboard command is configured
+	ta.app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModAlt))
+	ta.waitForText("Failed to copy filter: no terminal", testActionTimeout)
+
+	ta.postKey(tcell.KeyRight, tcell.ModShift)
+	ta.waitForTextViewFocus(testActionTimeout)
+	ta.postRune('y')
+	ta.waitForText("No value is selected", testActionTimeout)
+
+	// The message is removed after a while
+	ta.waitForNoText("No value is selected", 5*time.Second)
+	ta.requireText("quit and write output")
+}
+
+func TestUICopyCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clipboard")
+	ta := newTestAppWithDocument(t, `{"a":1}`, nil, "", func(doc *Document) {
+		doc.config.ClipboardCommand = []string{"sh", "-c", `cat > "$0"`, path}
+	})
+
+	// The configured command is used before OSC 52
+	ta.app.QueueEvent(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModAlt))
+	ta.waitForText("Copied filter to the clipboard", testActionTimeout)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, ".", string(contents))
+}
+
+func TestUISaveOutput(t *testing.T) {
+	ta := newTestApp(t, `{"saved":true}`, nil)
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	ta.openMenu()
+	ta.selectMenuItem(6)
+	ta.waitForText("Save output as", testActionTimeout)
+	ta.postRunes(path)
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.waitForText("saved", testActionTimeout)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{"saved":true}`, string(contents))
+
+	// Saving again asks before overwriting the file
+	ta.postKey(tcell.KeyEsc, tcell.ModNone)
+	ta.openMenu()
+	ta.selectMenuItem(6)
+	ta.waitForText("Save output as", testActionTimeout)
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.waitForText("Overwrite the following file?", testActionTimeout)
+	ta.postKey(tcell.KeyEsc, tcell.ModNone)
+	ta.waitForText("Save output as", testActionTimeout)
+}
+
+func TestUISaveOutputExitStatus(t *testing.T) {
+	ta := newTestAppWithDocument(t, `null`, nil, "gojq", func(doc *Document) {
+		doc.options.ExitStatus = true
+	})
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	// An output which would fail with --exit-status is still saved
+	ta.openMenu()
+	ta.selectMenuItem(6)
+	ta.waitForText("Save output as", testActionTimeout)
+	ta.postRunes(path)
+	ta.postKey(tcell.KeyEnter, tcell.ModNone)
+	ta.waitFor(func() bool {
+		contents, err := os.ReadFile(path)
+		return err == nil && string(contents) == "null\n"
+	}, "output to be saved", testActionTimeout)
+}
+
+func TestUIInsertPath(t *testing.T) {
+	ta := newTestApp(t, "{\n  \"items\": [\n    {\"app name\": 1}\n  ]\n}\n", nil)
+
+	ta.waitForText(`{"app name": 1}`, testActionTimeout)
+
+	// Click the first element of "items" in the input pane
+	ta.click(4, 3)
+	ta.waitForText(".items[0]   p insert path", testActionTimeout)
+
+	ta.postRune('p')
+	ta.waitForInputFieldFocus(testActionTimeout)
+	filterRow := ta.findRowOf("Filter") + 1
+	ta.waitFor(func() bool {
+		return strings.Contains(ta.row(filterRow), "║.items[0] ")
+	}, "path to be inserted", testActionTimeout)
+
+	// In a tree the path of the selected value is shown as it is moved
+	ta.postKey(tcell.KeyUp, tcell.ModShift)
+	ta.postRune('t')
+	ta.waitForText("0: {…} 1 key", testActionTimeout)
+	ta.postRunes("jjlj")
+	ta.waitForText(`.items[0]["app name"]   p insert path`, testActionTimeout)
+
+	ta.postRune('p')
+	ta.waitForInputFieldFocus(testActionTimeout)
+	ta.waitFor(func() bool {
+		return strings.Contains(ta.row(filterRow), `.items[0] | .items[0]["app name"]`)
+	}, "path to be appended", testActionTimeout)
+}
+
 func TestUIOverlayMenuToggle(t *testing.T) {
 	ta := newTestApp(t, `{"key":"value"}`, nil)
 
@@ -440,7 +869,64 @@ func TestUIOverlayMenuConfigure(t *testing.T) {
 		"Monochrome output (-M)",
 		"Force color (-C)",
 		"Sort keys (-S)",
+		"Indentation: 2 (-indent)",
+		"Indent with tabs (-tab)",
+		"Parse input in streaming fashion (-stream)",
+		"Stream input and report parse errors as values (-stream-errors)",
+		"Use application/json-seq records (-seq)",
+		"Set exit status from the last output (-e)",
 		"Hide input (left) viewing pane (-hide-input-pane)",
+		"Reload input files when they change (-watch)",
+		"Follow values streamed on stdin (-follow)",
+		"Follow mode: all (-follow-mode)",
+		"Retained values: 10000 (-follow-limit)",
+		"Output format: json (-output-format)",
+		"Input format: json (-input-format)",
+		"Show each file in its own tab (-tabs)",
+		"Run filter on: selected (-tab-scope)",
+	}
+
+	// Enum options cycle through their values
+	cycles := map[string][]string{
+		"Follow mode: all (-follow-mode)": {
+			"Follow mode: new (-follow-mode)",
+			"Follow mode: all (-follow-mode)",
+		},
+		"Output format: json (-output-format)": {
+			"Output format: yaml (-output-format)",
+			"Output format: csv (-output-format)",
+			"Output format: tsv (-output-format)",
+			"Output format: table (-output-format)",
+			"Output format: json (-output-format)",
+		},
+		"Input format: json (-input-format)": {
+			"Input format: yaml (-input-format)",
+			"Input format: toml (-input-format)",
+			"Input format: csv (-input-format)",
+			"Input format: tsv (-input-format)",
+			"Input format: xml (-input-format)",
+			"Input format: json (-input-format)",
+		},
+		"Run filter on: selected (-tab-scope)": {
+			"Run filter on: all (-tab-scope)",
+			"Run filter on: selected (-tab-scope)",
+		},
+	}
+
+	// Integer options are adjusted with +/- instead of toggled
+	adjustments := map[string][]struct {
+		key  rune
+		want string
+	}{
+		"Indentation: 2 (-indent)": {
+			{'+', "Indentation: 3 (-indent)"},
+			{'-', "Indentation: 2 (-indent)"},
+			{'-', "Indentation: 1 (-indent)"},
+		},
+		"Retained values: 10000 (-follow-limit)": {
+			{'+', "Retained values: 11000 (-follow-limit)"},
+			{'-', "Retained values: 10000 (-follow-limit)"},
+		},
 	}
 
 	ta.openMenu()
@@ -449,10 +935,40 @@ func TestUIOverlayMenuConfigure(t *testing.T) {
 	ta.waitForText("toggle", testActionTimeout)
 
 	for _, row := range rows {
-		ta.requireText("○ " + row)
+		if _, ok := adjustments[row]; ok {
+			ta.requireText("± " + row)
+		} else if _, ok := cycles[row]; ok {
+			ta.requireText("↻ " + row)
+		} else {
+			ta.requireText("○ " + row)
+		}
 	}
 
 	for i, row := range rows {
+		if steps, ok := adjustments[row]; ok {
+			for _, step := range steps {
+				ta.postRune(step.key)
+				ta.waitForText("± "+step.want, testActionTimeout)
+			}
+
+			if i < len(rows)-1 {
+				ta.postKey(tcell.KeyDown, tcell.ModNone)
+			}
+			continue
+		}
+
+		if values, ok := cycles[row]; ok {
+			for _, value := range values {
+				ta.postRune(' ')
+				ta.waitForText("↻ "+value, testActionTimeout)
+			}
+
+			if i < len(rows)-1 {
+				ta.postKey(tcell.KeyDown, tcell.ModNone)
+			}
+			continue
+		}
+
 		ta.postRune(' ')
 		ta.waitForText("● "+row, testActionTimeout)
 		ta.waitForNoText("○ "+row, testActionTimeout)
@@ -524,15 +1040,91 @@ func TestUIOverlayMenuKeybindings(t *testing.T) {
 	ta.openMenu()
 	ta.selectMenuItem(3)
 	ta.waitForText("Keybindings", testActionTimeout)
-	ta.requireText("submit-filter")
-	ta.requireText("Enter")
 	ta.requireText("move-down")
-	ta.requireText("toggle-menu")
 	ta.requireText("next-focus")
 	ta.requireText("Tab")
 	ta.requireText("scroll-to-bottom")
 	ta.requireText("scroll-to-top")
-	ta.requireText("quit")
+
+	// The list is longer than the screen, so scroll to its end
+	ta.postKey(tcell.KeyEnd, tcell.ModNone)
+	ta.waitForText("quit", testActionTimeout)
+	ta.requireText("submit-filter")
+	ta.requireText("Enter")
+	ta.requireText("toggle-menu")
 	ta.requireText("Ctrl-C")
 	ta.requireText("close")
 }
+
+func TestUIFollow(t *testing.T) {
+	r, w := io.Pipe()
+	t.Cleanup(func() { w.Close() })
+
+	ta := newTestAppWithDocument(t, "", nil, "exec", func(doc *Document) {
+		doc.follow = r
+		doc.options.Follow = true
+	})
+
+	// Values are added to the input pane as they arrive
+	_, err := io.WriteString(w, `{"id":1}`+"\n")
+	require.NoError(t, err)
+	ta.waitForText(`{"id":1}`, testActionTimeout)
+
+	_, err = io.WriteString(w, `{"id":2}`+"\n"+`{"id":3}`+"\n")
+	require.NoError(t, err)
+	ta.waitForText(`{"id":3}`, testActionTimeout)
+
+	rows := ta.rows()
+	one := slices.IndexFunc(rows, func(row string) bool { return strings.Contains(row, `{"id":1}`) })
+	two := slices.IndexFunc(rows, func(row string) bool { return strings.Contains(row, `{"id":2}`) })
+	require.Positive(t, one)
+	require.Greater(t, two, one)
+}
+
+func TestUIFollowNew(t *testing.T) {
+	r, w := io.Pipe()
+	t.Cleanup(func() { w.Close() })
+
+	ta := newTestAppWithDocument(t, "", nil, "gojq", func(doc *Document) {
+		doc.follow = r
+		doc.filter = `"v\(.id)"`
+		doc.options.Follow = true
+		doc.options.FollowMode = "new"
+	})
+
+	// The output of new values is added to the output of the old ones
+	_, err := io.WriteString(w, `{"id":1}`+"\n")
+	require.NoError(t, err)
+	ta.waitForText(`"v1"`, testActionTimeout)
+
+	_, err = io.WriteString(w, `{"id":2}`+"\n")
+	require.NoError(t, err)
+	ta.waitForText(`"v2"`, testActionTimeout)
+	ta.requireText(`"v1"`)
+
+	toggleFollow := func(want string) {
+		ta.openMenu()
+		ta.selectMenuItem(0)
+		ta.waitForText("Space/Enter", testActionTimeout)
+		ta.postKey(tcell.KeyHome, tcell.ModNone)
+		for range 18 {
+			ta.postKey(tcell.KeyDown, tcell.ModNone)
+		}
+		ta.postRune(' ')
+		ta.waitForText(want+" Follow values streamed on stdin", testActionTimeout)
+		ta.postKey(tcell.KeyCtrlUnderscore, tcell.ModNone)
+		ta.waitForNoText("Follow values streamed on stdin", testActionTimeout)
+	}
+
+	// Values which arrive while following is paused are filtered once it
+	// is resumed
+	toggleFollow("○")
+	_, err = io.WriteString(w, `{"id":3}`+"\n")
+	require.NoError(t, err)
+	time.Sleep(3 * followInterval)
+	ta.requireNoText(`"v3"`)
+
+	toggleFollow("●")
+	ta.waitForText(`"v3"`, testActionTimeout)
+	ta.requireText(`"v1"`)
+}
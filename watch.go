@@ -0,0 +1,102 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// watchInterval is how often watched input files are checked for changes.
+const watchInterval = 500 * time.Millisecond
+
+// fileWatcher polls a set of files and reports when any of them change.
+// Polling is used rather than filesystem notifications so that files which
+// are replaced (rather than written in place) by editors and other tools are
+// still picked up.
+type fileWatcher struct {
+	files []string
+	stats []fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFile(name string) fileStat {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileStat{}
+	}
+
+	return fileStat{modTime: fi.ModTime(), size: fi.Size(), exists: true}
+}
+
+func newFileWatcher(files []string) *fileWatcher {
+	w := &fileWatcher{files: files, stats: make([]fileStat, len(files))}
+	for i, name := range files {
+		w.stats[i] = statFile(name)
+	}
+
+	return w
+}
+
+// Changed returns true if any of the watched files have been modified since
+// the last call. Files which are temporarily missing are not reported until
+// they exist again.
+func (w *fileWatcher) Changed() bool {
+	changed := false
+	for i, name := range w.files {
+		st := statFile(name)
+		if !st.exists {
+			continue
+		}
+
+		if st != w.stats[i] {
+			w.stats[i] = st
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// readFile copies the decompressed contents of the named file to w and
+// returns its compression format.
+func readFile(w io.Writer, name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r, compression, err := decompress(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return compression, nil
+}
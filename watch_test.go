@@ -0,0 +1,46 @@
+// Copyright (C) 2026 Gregory Anders <greg@gpanders.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcherChanged(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "input.json")
+	require.NoError(t, os.WriteFile(name, []byte(`{"a":1}`), 0o644))
+
+	w := newFileWatcher([]string{name})
+	assert.False(t, w.Changed())
+
+	require.NoError(t, os.WriteFile(name, []byte(`{"a":12}`), 0o644))
+	assert.True(t, w.Changed())
+	assert.False(t, w.Changed())
+
+	// A missing file is not reported until it is recreated
+	require.NoError(t, os.Remove(name))
+	assert.False(t, w.Changed())
+
+	require.NoError(t, os.WriteFile(name, []byte(`{"a":123}`), 0o644))
+	assert.True(t, w.Changed())
+}